@@ -0,0 +1,359 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+)
+
+var bulkURL string
+var bulkIndex string
+var bulkDataStream string
+var bulkPipeline string
+var bulkType string
+var bulkRFC string
+var bulkLEEFVersion string
+var bulkLEEFDelimiter string
+var bulkBatchSize int
+var bulkMaxRetries int
+var bulkUser string
+var bulkPassword string
+var bulkAPIKey string
+var bulkGzip bool
+var bulkTLSCA string
+var bulkTLSInsecure bool
+var bulkRate int
+var bulkCount int
+
+// bulkDatePattern matches %{+layout} in index names, with a Go time layout
+var bulkDatePattern = regexp.MustCompile(`%\{\+([^}]+)\}`)
+
+var bulkCmd = &cobra.Command{
+	Use:     "bulk",
+	Aliases: []string{"elasticsearch", "opensearch"},
+	Short:   "Index fake events into Elasticsearch/OpenSearch with _bulk",
+	Long: `Index fake events into an Elasticsearch-compatible cluster using the _bulk API.
+
+Index names may contain a date pattern %{+layout} using a Go time layout,
+expanded in UTC when each batch is built:
+  --index "fakedata-%{+2006.01.02}"   ->  fakedata-2024.01.15
+
+With --data-stream, documents are sent with the "create" action to the named
+data stream. Every document gets an @timestamp field.
+
+Items rejected with 429 (or 503) are retried with backoff; other item errors
+are reported by type and counted as failed. Non-JSON event types are indexed
+as {"message": "..."}.
+
+Example:
+  fakedata bulk --url http://localhost:9200 --rate 1000 --batch-size 500
+  fakedata bulk --url http://localhost:9200 --index "fw-%{+2006.01.02}" --type firewall
+  fakedata bulk --url http://localhost:9200 --data-stream logs-fakedata-default
+  fakedata bulk --url https://es:9200 --api-key $ES_API_KEY --pipeline geoip --gzip
+`,
+	RunE: runBulk,
+}
+
+func init() {
+	bulkCmd.Flags().StringVar(&bulkURL, "url", "http://localhost:9200", "Cluster URL")
+	bulkCmd.Flags().StringVar(&bulkIndex, "index", "fakedata-%{+2006.01.02}", "Index name, may contain %{+layout} date patterns")
+	bulkCmd.Flags().StringVar(&bulkDataStream, "data-stream", "", "Data stream name (uses the create action)")
+	bulkCmd.Flags().StringVar(&bulkPipeline, "pipeline", "", "Ingest pipeline to run documents through")
+	bulkCmd.Flags().StringVar(&bulkType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	bulkCmd.Flags().StringVar(&bulkRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	bulkCmd.Flags().StringVar(&bulkLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	bulkCmd.Flags().StringVar(&bulkLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	bulkCmd.Flags().IntVar(&bulkBatchSize, "batch-size", 500, "Documents per _bulk request")
+	bulkCmd.Flags().IntVar(&bulkMaxRetries, "max-retries", 3, "Retries for rejected items and failed requests")
+	bulkCmd.Flags().StringVar(&bulkUser, "user", "", "Basic auth username")
+	bulkCmd.Flags().StringVar(&bulkPassword, "password", "", "Basic auth password")
+	bulkCmd.Flags().StringVar(&bulkAPIKey, "api-key", "", "Encoded API key for the Authorization header")
+	bulkCmd.Flags().BoolVar(&bulkGzip, "gzip", false, "Gzip request bodies")
+	bulkCmd.Flags().StringVar(&bulkTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	bulkCmd.Flags().BoolVar(&bulkTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	bulkCmd.Flags().IntVar(&bulkRate, "rate", 10, "Documents per second")
+	bulkCmd.Flags().IntVar(&bulkCount, "count", 0, "Total documents to send (0 = unlimited)")
+}
+
+// bulkDoc is a document with the index it is destined for
+type bulkDoc struct {
+	index  string
+	source []byte
+}
+
+// bulkResponse is the subset of the _bulk response used to detect item failures
+type bulkResponse struct {
+	Errors bool                        `json:"errors"`
+	Items  []map[string]bulkItemResult `json:"items"`
+}
+
+type bulkItemResult struct {
+	Index  string `json:"_index"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// bulkIndexName expands date patterns in the index name
+func bulkIndexName(pattern string, now time.Time) string {
+	return bulkDatePattern.ReplaceAllStringFunc(pattern, func(m string) string {
+		layout := bulkDatePattern.FindStringSubmatch(m)[1]
+		return now.UTC().Format(layout)
+	})
+}
+
+// bulkDocument builds the document source, adding @timestamp
+func bulkDocument(event []byte, now time.Time) ([]byte, error) {
+	doc := map[string]interface{}{"message": string(event)}
+	if generators.IsJSONEventType(bulkType) {
+		var err error
+		if doc, err = decodeEvent(event); err != nil {
+			return nil, err
+		}
+	}
+	ts, ok := eventTime(doc)
+	if !ok {
+		ts = now
+	}
+	doc["@timestamp"] = ts.UTC().Format(time.RFC3339Nano)
+	return sonic.Marshal(doc)
+}
+
+// bulkBody encodes documents as _bulk NDJSON
+func bulkBody(docs []bulkDoc) []byte {
+	action := "index"
+	if bulkDataStream != "" {
+		action = "create"
+	}
+
+	var buf bytes.Buffer
+	for _, d := range docs {
+		meta, _ := sonic.Marshal(map[string]map[string]string{action: {"_index": d.index}})
+		buf.Write(meta)
+		buf.WriteByte('\n')
+		buf.Write(d.source)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// bulkSend posts documents to _bulk, retrying whole-request failures and
+// rejected items. Returns the number of documents indexed.
+func bulkSend(client *http.Client, endpoint string, docs []bulkDoc, stats, items *httpStats, errorTypes map[string]string) int {
+	indexed := 0
+	pending := docs
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(httpRetryDelay(nil, attempt-1))
+		}
+
+		body := bulkBody(pending)
+		encoding := ""
+		if bulkGzip {
+			compressed, err := gzipBytes(body)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error compressing: %v\n", err)
+				return indexed
+			}
+			body, encoding = compressed, "gzip"
+		}
+
+		status, respBody, err := doHTTPWithRetry(client, func() (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			if encoding != "" {
+				req.Header.Set("Content-Encoding", encoding)
+			}
+			if bulkAPIKey != "" {
+				req.Header.Set("Authorization", "ApiKey "+bulkAPIKey)
+			} else if bulkUser != "" {
+				req.SetBasicAuth(bulkUser, bulkPassword)
+			}
+			return req, nil
+		}, bulkMaxRetries, stats)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending bulk request: %v\n", err)
+			stats.recordFailed(len(pending))
+			return indexed
+		}
+		if status != http.StatusOK {
+			fmt.Fprintf(os.Stderr, "Bulk request failed with status %d: %s\n", status, strings.TrimSpace(string(respBody)))
+			stats.recordFailed(len(pending))
+			return indexed
+		}
+
+		var resp bulkResponse
+		if err := sonic.Unmarshal(respBody, &resp); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid bulk response: %v\n", err)
+			stats.recordFailed(len(pending))
+			return indexed
+		}
+		if len(resp.Items) != len(pending) {
+			fmt.Fprintf(os.Stderr, "Bulk response has %d items for %d documents\n", len(resp.Items), len(pending))
+			stats.recordFailed(len(pending))
+			return indexed
+		}
+
+		var retry []bulkDoc
+		for i, item := range resp.Items {
+			for _, result := range item {
+				items.recordStatus(result.Status)
+				switch {
+				case result.Status >= 200 && result.Status < 300:
+					indexed++
+				case result.Status == http.StatusTooManyRequests || result.Status == http.StatusServiceUnavailable:
+					retry = append(retry, pending[i])
+				default:
+					if result.Error != nil {
+						if _, seen := errorTypes[result.Error.Type]; !seen {
+							errorTypes[result.Error.Type] = result.Error.Reason
+							fmt.Fprintf(os.Stderr, "Item failed: %s: %s\n", result.Error.Type, result.Error.Reason)
+						}
+					}
+					items.recordFailed(1)
+				}
+			}
+		}
+
+		if len(retry) > 0 && attempt >= bulkMaxRetries {
+			fmt.Fprintf(os.Stderr, "Giving up on %d rejected items after %d retries\n", len(retry), bulkMaxRetries)
+			items.recordFailed(len(retry))
+			return indexed
+		}
+		pending = retry
+	}
+
+	return indexed
+}
+
+func runBulk(cmd *cobra.Command, args []string) error {
+	if !generators.ValidEventType(bulkType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", bulkType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(bulkLEEFVersion, bulkLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if bulkBatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	if bulkAPIKey != "" && bulkUser != "" {
+		return fmt.Errorf("only one of --api-key or --user may be set")
+	}
+
+	endpoint := strings.TrimRight(bulkURL, "/") + "/_bulk"
+	if bulkPipeline != "" {
+		endpoint += "?pipeline=" + url.QueryEscape(bulkPipeline)
+	}
+
+	tlsConfig, err := buildClientTLSConfig(bulkTLSCA, "", "", "", bulkTLSInsecure)
+	if err != nil {
+		return err
+	}
+	client := newHTTPClient(30*time.Second, tlsConfig, 1)
+	stats := newHTTPStats()
+	items := newHTTPStatsTitled("Item statuses")
+	errorTypes := make(map[string]string)
+
+	target := bulkIndex
+	if bulkDataStream != "" {
+		target = bulkDataStream + " (data stream)"
+	}
+	fmt.Printf("Indexing fake %s documents into %s at %s (%d docs/s, batch size %d)\n",
+		bulkType, target, bulkURL, bulkRate, bulkBatchSize)
+	if bulkPipeline != "" {
+		fmt.Printf("Using ingest pipeline: %s\n", bulkPipeline)
+	}
+	if bulkCount > 0 {
+		fmt.Printf("Will send %d documents total\n", bulkCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(bulkRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	generated := 0
+	startTime := time.Now()
+	opts := generators.Options{RFC: bulkRFC, LEEF: leef}
+	batch := make([]bulkDoc, 0, bulkBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		before := sent
+		sent += bulkSend(client, endpoint, batch, stats, items, errorTypes)
+		batch = batch[:0]
+		if sent/1000 > before/1000 {
+			fmt.Printf("Indexed %d documents...\n", sent)
+		}
+	}
+	finish := func(format string) {
+		flush()
+		fmt.Printf(format, sent, time.Since(startTime))
+		stats.Print()
+		items.Print()
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Indexed %d documents in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(bulkType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+			now := time.Now()
+			source, err := bulkDocument(event, now)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding document: %v\n", err)
+				continue
+			}
+			index := bulkDataStream
+			if index == "" {
+				index = bulkIndexName(bulkIndex, now)
+			}
+			batch = append(batch, bulkDoc{index: index, source: source})
+			generated++
+
+			if len(batch) >= bulkBatchSize {
+				flush()
+			}
+
+			if bulkCount > 0 && generated >= bulkCount {
+				finish("Completed. Indexed %d documents in %v\n")
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var certsOutDir string
+var certsHosts string
+var certsClientName string
+var certsDays int
+var certsKeyType string
+var certsForce bool
+
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Generate a throwaway CA plus server and client certificates",
+	Long: `Generate a throwaway certificate authority and a server and client
+certificate signed by it, for testing TLS receivers and mTLS end to end.
+
+Files written to --out-dir:
+  ca.pem, ca-key.pem          CA certificate and key
+  server.pem, server-key.pem  Server certificate for --hosts (DNS names and IPs)
+  client.pem, client-key.pem  Client certificate for --client-name
+
+These certificates are for local testing only.
+
+Example:
+  fakedata certs --out-dir ./certs --hosts localhost,127.0.0.1
+  fakedata syslog --transport tls --port 6514 --tls-ca certs/ca.pem \
+    --tls-cert certs/client.pem --tls-key certs/client-key.pem
+`,
+	RunE: runCerts,
+}
+
+func init() {
+	certsCmd.Flags().StringVar(&certsOutDir, "out-dir", "certs", "Directory to write certificates to")
+	certsCmd.Flags().StringVar(&certsHosts, "hosts", "localhost,127.0.0.1,::1", "Server certificate DNS names and IPs, comma-separated")
+	certsCmd.Flags().StringVar(&certsClientName, "client-name", "fakedata-client", "Client certificate common name")
+	certsCmd.Flags().IntVar(&certsDays, "days", 30, "Validity in days")
+	certsCmd.Flags().StringVar(&certsKeyType, "key-type", "ecdsa", "Key type: ecdsa (P-256) or rsa (2048)")
+	certsCmd.Flags().BoolVar(&certsForce, "force", false, "Overwrite existing files")
+}
+
+// certsGenerateKey creates a private key of the configured type
+func certsGenerateKey() (crypto.Signer, error) {
+	switch certsKeyType {
+	case "ecdsa":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "rsa":
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	return nil, fmt.Errorf("invalid key type: %s (must be ecdsa or rsa)", certsKeyType)
+}
+
+// certsWrite PEM-encodes a certificate and its key into the output directory
+func certsWrite(name string, der []byte, key crypto.Signer) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s key: %w", name, err)
+	}
+	files := []struct {
+		path  string
+		block *pem.Block
+		mode  os.FileMode
+	}{
+		{filepath.Join(certsOutDir, name+".pem"), &pem.Block{Type: "CERTIFICATE", Bytes: der}, 0o644},
+		{filepath.Join(certsOutDir, name+"-key.pem"), &pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}, 0o600},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(f.path, pem.EncodeToMemory(f.block), f.mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.path, err)
+		}
+	}
+	return nil
+}
+
+// certsSign issues a certificate from template, signed by parent/parentKey
+// (self-signed when parent is nil)
+func certsSign(template, parent *x509.Certificate, parentKey crypto.Signer) ([]byte, crypto.Signer, *x509.Certificate, error) {
+	key, err := certsGenerateKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Duration(certsDays) * 24 * time.Hour)
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create certificate %q: %w", template.Subject.CommonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return der, key, cert, nil
+}
+
+func runCerts(cmd *cobra.Command, args []string) error {
+	if certsDays < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+	if err := os.MkdirAll(certsOutDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", certsOutDir, err)
+	}
+	if !certsForce {
+		for _, name := range []string{"ca.pem", "server.pem", "client.pem"} {
+			if _, err := os.Stat(filepath.Join(certsOutDir, name)); err == nil {
+				return fmt.Errorf("%s already exists in %s (use --force to overwrite)", name, certsOutDir)
+			}
+		}
+	}
+
+	caDER, caKey, caCert, err := certsSign(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "fakedata test CA", Organization: []string{"fakedata"}},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := certsWrite("ca", caDER, caKey); err != nil {
+		return err
+	}
+
+	server := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "fakedata server", Organization: []string{"fakedata"}},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	var hosts []string
+	for _, h := range strings.Split(certsHosts, ",") {
+		if h = strings.TrimSpace(h); h == "" {
+			continue
+		}
+		hosts = append(hosts, h)
+		if ip := net.ParseIP(h); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else {
+			server.DNSNames = append(server.DNSNames, h)
+		}
+	}
+	if len(server.DNSNames) > 0 {
+		server.Subject.CommonName = server.DNSNames[0]
+	}
+	serverDER, serverKey, _, err := certsSign(server, caCert, caKey)
+	if err != nil {
+		return err
+	}
+	if err := certsWrite("server", serverDER, serverKey); err != nil {
+		return err
+	}
+
+	clientDER, clientKey, _, err := certsSign(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: certsClientName, Organization: []string{"fakedata"}},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+	if err != nil {
+		return err
+	}
+	if err := certsWrite("client", clientDER, clientKey); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote test certificates to %s (valid %d days, %s keys)\n", certsOutDir, certsDays, certsKeyType)
+	fmt.Printf("  CA:     ca.pem, ca-key.pem\n")
+	fmt.Printf("  Server: server.pem, server-key.pem (%s)\n", strings.Join(hosts, ", "))
+	fmt.Printf("  Client: client.pem, client-key.pem (CN=%s)\n", certsClientName)
+	return nil
+}
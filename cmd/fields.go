@@ -0,0 +1,109 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytefreezer/fakedata/generators"
+)
+
+// eventField returns the string form of a generated event field, or "" if
+// the field is missing. Objects and arrays are rendered as JSON.
+func eventField(event map[string]interface{}, name string) string {
+	v, ok := event[name]
+	if !ok || v == nil {
+		return ""
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		b, err := eventJSON.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// eventJSON decodes generated JSON events keeping integers exact, and
+// encodes nested values with sorted keys
+var eventJSON = sonic.Config{UseInt64: true, SortMapKeys: true}.Froze()
+
+// decodeEvent returns the top-level fields of a generated JSON event
+func decodeEvent(event []byte) (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if err := eventJSON.Unmarshal(event, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// eventTime returns the time in the timestamp field of a JSON event, given
+// in RFC 3339 or in Suricata's EVE layout
+func eventTime(fields map[string]interface{}) (time.Time, bool) {
+	ts, _ := fields["timestamp"].(string)
+	for _, layout := range []string{time.RFC3339Nano, generators.EVETimeLayout} {
+		if t, err := time.Parse(layout, ts); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// severityNames maps syslog severities to their conventional level names
+var severityNames = []string{"emergency", "alert", "critical", "error", "warning", "notice", "info", "debug"}
+
+// eventTemplateValues returns the values a template can reference for one
+// generated event: "type" plus the fields from eventFieldValues
+func eventTemplateValues(eventType string, event []byte) map[string]string {
+	values := eventFieldValues(eventType, event)
+	if _, ok := values["type"]; !ok {
+		values["type"] = eventType
+	}
+	return values
+}
+
+// eventFieldValues returns the fields of one generated event: every
+// top-level field of JSON events, and hostname, process, facility and
+// severity for syslog-style events
+func eventFieldValues(eventType string, event []byte) map[string]string {
+	values := make(map[string]string)
+	if generators.IsJSONEventType(eventType) {
+		if fields, err := decodeEvent(event); err == nil {
+			for k := range fields {
+				values[k] = eventField(fields, k)
+			}
+		}
+		return values
+	}
+	if h, ok := generators.ParseSyslogHeader(string(event)); ok {
+		values["hostname"] = h.Hostname
+		values["process"] = h.AppName
+		values["facility"] = strconv.Itoa(h.Facility())
+		values["severity"] = severityNames[h.Severity()]
+	}
+	return values
+}
+
+var templatePlaceholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// expandTemplate replaces {name} placeholders with values; missing or empty
+// values become "unknown"
+func expandTemplate(tmpl string, values map[string]string) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	return templatePlaceholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		if v := values[m[1:len(m)-1]]; v != "" {
+			return v
+		}
+		return "unknown"
+	})
+}
@@ -0,0 +1,125 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+)
+
+var filePath string
+var fileType string
+var fileRFC string
+var fileLEEFVersion string
+var fileLEEFDelimiter string
+var fileTruncate bool
+var fileRate int
+var fileCount int
+
+var fileCmd = &cobra.Command{
+	Use:   "file",
+	Short: "Append fake events to a log file",
+	Long: `Append fake events to a log file, one event per line, for testing file
+tailing collectors (Filebeat, Fluent Bit tail, Vector file, Promtail, ...).
+
+Events are written as they are generated, so a collector tailing the file
+sees a steady stream. The multiline types write stack traces, pretty JSON and
+indented logs that span several lines, for testing multiline aggregation.
+
+Example:
+  fakedata file --path /var/log/fakedata/app.log --rate 100
+  fakedata file --path app.log --type multiline-java --rate 10 --count 1000
+  fakedata file --path mixed.log --type multiline --truncate
+  fakedata file --path syslog.log --type firewall --rfc 5424
+`,
+	RunE: runFile,
+}
+
+func init() {
+	fileCmd.Flags().StringVar(&filePath, "path", "fakedata.log", "File to append events to")
+	fileCmd.Flags().StringVar(&fileType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	fileCmd.Flags().StringVar(&fileRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	fileCmd.Flags().StringVar(&fileLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	fileCmd.Flags().StringVar(&fileLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	fileCmd.Flags().BoolVar(&fileTruncate, "truncate", false, "Truncate the file before writing instead of appending")
+	fileCmd.Flags().IntVar(&fileRate, "rate", 10, "Events per second")
+	fileCmd.Flags().IntVar(&fileCount, "count", 0, "Total events to write (0 = unlimited)")
+}
+
+func runFile(cmd *cobra.Command, args []string) error {
+	if !generators.ValidEventType(fileType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", fileType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(fileLEEFVersion, fileLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if fileTruncate {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(filePath, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	fmt.Printf("Writing fake %s events to %s at %d events/s\n", fileType, filePath, fileRate)
+	if fileCount > 0 {
+		fmt.Printf("Will write %d events total\n", fileCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(fileRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	written := 0
+	lines := 0
+	startTime := time.Now()
+	opts := generators.Options{RFC: fileRFC, LEEF: leef}
+
+	for {
+		select {
+		case <-sigChan:
+			fmt.Printf("\nStopped. Wrote %d events (%d lines) in %v\n", written, lines, time.Since(startTime))
+			return nil
+		case <-ticker.C:
+			data, err := generators.GenerateEvent(fileType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+
+			data = append(data, '\n')
+			if _, err := f.Write(data); err != nil {
+				return fmt.Errorf("failed to write to %s: %w", filePath, err)
+			}
+
+			written++
+			lines += strings.Count(string(data), "\n")
+			if written%1000 == 0 {
+				fmt.Printf("Wrote %d events...\n", written)
+			}
+
+			if fileCount > 0 && written >= fileCount {
+				fmt.Printf("Completed. Wrote %d events (%d lines) in %v\n", written, lines, time.Since(startTime))
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+)
+
+var forwardHost string
+var forwardPort int
+var forwardTag string
+var forwardType string
+var forwardRFC string
+var forwardLEEFVersion string
+var forwardLEEFDelimiter string
+var forwardMode string
+var forwardBatchSize int
+var forwardAck bool
+var forwardAckTimeout time.Duration
+var forwardSharedKey string
+var forwardSelfHostname string
+var forwardUser string
+var forwardPassword string
+var forwardTLS bool
+var forwardTLSCA string
+var forwardTLSCert string
+var forwardTLSKey string
+var forwardTLSInsecure bool
+var forwardMaxRetries int
+var forwardRate int
+var forwardCount int
+
+var forwardCmd = &cobra.Command{
+	Use:     "forward",
+	Aliases: []string{"fluent"},
+	Short:   "Send fake events with the Fluentd Forward protocol",
+	Long: `Send fake events to a Fluentd or Fluent Bit forward input over TCP or TLS.
+
+Modes:
+  message     One [tag, time, record] message per event
+  forward     [tag, [[time, record], ...]] batches
+  packed      PackedForward: batches as a msgpack byte stream
+  compressed  CompressedPackedForward: gzip-compressed PackedForward
+
+JSON events are sent as records with their fields; other event types are sent
+as {"message": "<line>"}. Timestamps use the EventTime extension (nanosecond
+precision).
+
+With --ack every chunk carries a chunk ID and is resent after a reconnect if
+the server does not acknowledge it within --ack-timeout. --shared-key performs
+the HELO/PING/PONG handshake used by secure_forward and Fluent Bit's
+forward.security settings.
+
+Example:
+  fakedata forward --host localhost --port 24224 --rate 100
+  fakedata forward --mode compressed --batch-size 500 --ack --type firewall
+  fakedata forward --tls --tls-ca ca.pem --shared-key secret --self-hostname loadgen
+`,
+	RunE: runForward,
+}
+
+func init() {
+	forwardCmd.Flags().StringVar(&forwardHost, "host", "127.0.0.1", "Target host")
+	forwardCmd.Flags().IntVar(&forwardPort, "port", 24224, "Target port")
+	forwardCmd.Flags().StringVar(&forwardTag, "tag", "", "Event tag (default fakedata.<type>)")
+	forwardCmd.Flags().StringVar(&forwardType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	forwardCmd.Flags().StringVar(&forwardRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	forwardCmd.Flags().StringVar(&forwardLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	forwardCmd.Flags().StringVar(&forwardLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	forwardCmd.Flags().StringVar(&forwardMode, "mode", "forward", "Protocol mode: message, forward, packed or compressed")
+	forwardCmd.Flags().IntVar(&forwardBatchSize, "batch-size", 100, "Events per chunk (ignored in message mode)")
+	forwardCmd.Flags().BoolVar(&forwardAck, "ack", false, "Request chunk acknowledgements")
+	forwardCmd.Flags().DurationVar(&forwardAckTimeout, "ack-timeout", 10*time.Second, "Time to wait for an acknowledgement")
+	forwardCmd.Flags().StringVar(&forwardSharedKey, "shared-key", "", "Shared key for the handshake")
+	forwardCmd.Flags().StringVar(&forwardSelfHostname, "self-hostname", "", "Hostname sent in the handshake (default os hostname)")
+	forwardCmd.Flags().StringVar(&forwardUser, "user", "", "Username for handshake user authentication")
+	forwardCmd.Flags().StringVar(&forwardPassword, "password", "", "Password for handshake user authentication")
+	forwardCmd.Flags().BoolVar(&forwardTLS, "tls", false, "Connect with TLS")
+	forwardCmd.Flags().StringVar(&forwardTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	forwardCmd.Flags().StringVar(&forwardTLSCert, "tls-cert", "", "Client certificate file")
+	forwardCmd.Flags().StringVar(&forwardTLSKey, "tls-key", "", "Client private key file")
+	forwardCmd.Flags().BoolVar(&forwardTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	forwardCmd.Flags().IntVar(&forwardMaxRetries, "max-retries", 3, "Reconnect attempts per chunk")
+	forwardCmd.Flags().IntVar(&forwardRate, "rate", 10, "Events per second")
+	forwardCmd.Flags().IntVar(&forwardCount, "count", 0, "Total events to send (0 = unlimited)")
+}
+
+// forwardRecord builds the record map for one generated event
+func forwardRecord(opts generators.Options) (map[string]interface{}, error) {
+	event, err := generators.GenerateEvent(forwardType, opts)
+	if err != nil {
+		return nil, err
+	}
+	if generators.IsJSONEventType(forwardType) {
+		return decodeEvent(event)
+	}
+	return map[string]interface{}{"message": string(event)}, nil
+}
+
+func runForward(cmd *cobra.Command, args []string) error {
+	switch forwardMode {
+	case "message", "forward", "packed", "compressed":
+	default:
+		return fmt.Errorf("invalid mode: %s (must be message, forward, packed or compressed)", forwardMode)
+	}
+	if !generators.ValidEventType(forwardType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", forwardType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(forwardLEEFVersion, forwardLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if forwardBatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	batchSize := forwardBatchSize
+	if forwardMode == "message" {
+		batchSize = 1
+	}
+	tag := forwardTag
+	if tag == "" {
+		tag = "fakedata." + forwardType
+	}
+
+	auth := forwardAuth{
+		sharedKey:    forwardSharedKey,
+		selfHostname: forwardSelfHostname,
+		username:     forwardUser,
+		password:     forwardPassword,
+	}
+	if auth.selfHostname == "" {
+		auth.selfHostname, _ = os.Hostname()
+	}
+
+	var tlsConfig *tls.Config
+	if forwardTLS {
+		var err error
+		tlsConfig, err = buildClientTLSConfig(forwardTLSCA, forwardTLSCert, forwardTLSKey, forwardHost, forwardTLSInsecure)
+		if err != nil {
+			return err
+		}
+	}
+
+	addr := fmt.Sprintf("%s:%d", forwardHost, forwardPort)
+	conn, err := dialForward(addr, tlsConfig, auth, forwardAckTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	fmt.Printf("Sending fake %s events to Forward %s (tag %s, %s mode) at %d events/s\n",
+		forwardType, addr, tag, forwardMode, forwardRate)
+	if forwardCount > 0 {
+		fmt.Printf("Will send %d events total\n", forwardCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(forwardRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	generated := 0
+	dropped := 0
+	acked := 0
+	reconnects := 0
+	startTime := time.Now()
+	opts := generators.Options{RFC: forwardRFC, LEEF: leef}
+	var batch []forwardEntry
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		defer func() { batch = batch[:0] }()
+
+		chunk := ""
+		if forwardAck {
+			chunk = newForwardChunkID()
+		}
+		msgs, err := encodeForwardMessages(forwardMode, tag, batch, chunk)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding chunk: %v\n", err)
+			dropped += len(batch)
+			return
+		}
+
+		for attempt := 0; ; attempt++ {
+			if conn == nil {
+				conn, err = dialForward(addr, tlsConfig, auth, forwardAckTimeout)
+				if err == nil {
+					reconnects++
+				}
+			}
+			if conn != nil {
+				if err = conn.send(msgs, chunk, forwardAckTimeout); err == nil {
+					break
+				}
+				conn.Close()
+				conn = nil
+			}
+			if attempt >= forwardMaxRetries {
+				fmt.Fprintf(os.Stderr, "Error sending chunk, dropping %d events: %v\n", len(batch), err)
+				dropped += len(batch)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Error sending chunk, reconnecting: %v\n", err)
+			time.Sleep(httpRetryDelay(nil, attempt))
+		}
+
+		if chunk != "" {
+			acked++
+		}
+		before := sent
+		sent += len(batch)
+		if sent/1000 > before/1000 {
+			fmt.Printf("Sent %d events...\n", sent)
+		}
+	}
+	finish := func(format string) {
+		flush()
+		fmt.Printf(format, sent, time.Since(startTime))
+		if forwardAck {
+			fmt.Printf("Acknowledged chunks: %d\n", acked)
+		}
+		if reconnects > 0 || dropped > 0 {
+			fmt.Printf("Reconnects: %d, dropped: %d\n", reconnects, dropped)
+		}
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d events in %v\n")
+			return nil
+		case <-ticker.C:
+			record, err := forwardRecord(opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+			batch = append(batch, forwardEntry{time: forwardEventTime(time.Now()), record: record})
+			generated++
+
+			if len(batch) >= batchSize {
+				flush()
+			}
+
+			if forwardCount > 0 && generated >= forwardCount {
+				finish("Completed. Sent %d events in %v\n")
+				return nil
+			}
+		}
+	}
+}
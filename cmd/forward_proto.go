@@ -0,0 +1,260 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// forwardEventTime is the Forward protocol EventTime extension (type 0):
+// seconds and nanoseconds as two big-endian uint32s
+type forwardEventTime time.Time
+
+func init() {
+	msgpack.RegisterExt(0, (*forwardEventTime)(nil))
+}
+
+func (t *forwardEventTime) MarshalMsgpack() ([]byte, error) {
+	tm := time.Time(*t)
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b[0:4], uint32(tm.Unix()))
+	binary.BigEndian.PutUint32(b[4:8], uint32(tm.Nanosecond()))
+	return b, nil
+}
+
+func (t *forwardEventTime) UnmarshalMsgpack(b []byte) error {
+	if len(b) != 8 {
+		return fmt.Errorf("invalid EventTime length %d", len(b))
+	}
+	*t = forwardEventTime(time.Unix(int64(binary.BigEndian.Uint32(b[0:4])), int64(binary.BigEndian.Uint32(b[4:8]))))
+	return nil
+}
+
+// forwardEntry is a single [time, record] pair
+type forwardEntry struct {
+	time   forwardEventTime
+	record map[string]interface{}
+}
+
+// forwardAuth holds the shared-key handshake settings
+type forwardAuth struct {
+	sharedKey    string
+	selfHostname string
+	username     string
+	password     string
+}
+
+// forwardConn is a connection to a Forward input
+type forwardConn struct {
+	conn net.Conn
+	enc  *msgpack.Encoder
+	dec  *msgpack.Decoder
+}
+
+// dialForward connects to addr over TCP or TLS and performs the shared-key
+// handshake when auth.sharedKey is set
+func dialForward(addr string, tlsConfig *tls.Config, auth forwardAuth, timeout time.Duration) (*forwardConn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	fc := &forwardConn{conn: conn, enc: msgpack.NewEncoder(conn), dec: msgpack.NewDecoder(conn)}
+	if auth.sharedKey != "" {
+		conn.SetDeadline(time.Now().Add(timeout))
+		if err := fc.handshake(auth); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.SetDeadline(time.Time{})
+	}
+	return fc, nil
+}
+
+// forwardDigest returns the hex SHA-512 of the concatenated parts
+func forwardDigest(parts ...[]byte) string {
+	h := sha512.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// forwardBytes accepts a msgpack str or bin value as bytes
+func forwardBytes(v interface{}) []byte {
+	switch b := v.(type) {
+	case []byte:
+		return b
+	case string:
+		return []byte(b)
+	}
+	return nil
+}
+
+// handshake answers the server's HELO with a PING and verifies its PONG
+func (fc *forwardConn) handshake(auth forwardAuth) error {
+	var helo []interface{}
+	if err := fc.dec.Decode(&helo); err != nil {
+		return fmt.Errorf("failed to read HELO: %w", err)
+	}
+	if len(helo) != 2 || helo[0] != "HELO" {
+		return fmt.Errorf("unexpected handshake message: %v", helo)
+	}
+	options, _ := helo[1].(map[string]interface{})
+	nonce := forwardBytes(options["nonce"])
+	userSalt := forwardBytes(options["auth"])
+
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	sharedKeySalt := []byte(hex.EncodeToString(salt))
+
+	passwordDigest := ""
+	if len(userSalt) > 0 {
+		passwordDigest = forwardDigest(userSalt, []byte(auth.username), []byte(auth.password))
+	}
+	ping := []interface{}{
+		"PING",
+		auth.selfHostname,
+		sharedKeySalt,
+		forwardDigest(sharedKeySalt, []byte(auth.selfHostname), nonce, []byte(auth.sharedKey)),
+		auth.username,
+		passwordDigest,
+	}
+	if err := fc.enc.Encode(ping); err != nil {
+		return fmt.Errorf("failed to send PING: %w", err)
+	}
+
+	var pong []interface{}
+	if err := fc.dec.Decode(&pong); err != nil {
+		return fmt.Errorf("failed to read PONG: %w", err)
+	}
+	if len(pong) != 5 || pong[0] != "PONG" {
+		return fmt.Errorf("unexpected handshake message: %v", pong)
+	}
+	if ok, _ := pong[1].(bool); !ok {
+		if reason := forwardBytes(pong[2]); len(reason) > 0 {
+			return fmt.Errorf("authentication failed: %s", reason)
+		}
+		return fmt.Errorf("authentication failed")
+	}
+	serverHostname := forwardBytes(pong[3])
+	want := forwardDigest(sharedKeySalt, serverHostname, nonce, []byte(auth.sharedKey))
+	if string(forwardBytes(pong[4])) != want {
+		return fmt.Errorf("server %s failed shared key verification", serverHostname)
+	}
+	return nil
+}
+
+// newForwardChunkID returns a random base64 chunk ID for acknowledgements
+func newForwardChunkID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// encodeForwardMessages encodes entries in the given mode. Message mode
+// yields one message per entry; the other modes yield a single message.
+func encodeForwardMessages(mode, tag string, entries []forwardEntry, chunk string) ([][]byte, error) {
+	option := map[string]interface{}{"size": len(entries)}
+	if chunk != "" {
+		option["chunk"] = chunk
+	}
+
+	var msgs []interface{}
+	switch mode {
+	case "message":
+		for i := range entries {
+			msg := []interface{}{tag, &entries[i].time, entries[i].record}
+			if chunk != "" {
+				msg = append(msg, map[string]interface{}{"chunk": chunk})
+			}
+			msgs = append(msgs, msg)
+		}
+	case "forward":
+		pairs := make([]interface{}, len(entries))
+		for i := range entries {
+			pairs[i] = []interface{}{&entries[i].time, entries[i].record}
+		}
+		msgs = append(msgs, []interface{}{tag, pairs, option})
+	case "packed", "compressed":
+		var buf bytes.Buffer
+		enc := msgpack.NewEncoder(&buf)
+		for i := range entries {
+			if err := enc.Encode([]interface{}{&entries[i].time, entries[i].record}); err != nil {
+				return nil, err
+			}
+		}
+		data := buf.Bytes()
+		if mode == "compressed" {
+			var err error
+			if data, err = gzipBytes(data); err != nil {
+				return nil, err
+			}
+			option["compressed"] = "gzip"
+		}
+		msgs = append(msgs, []interface{}{tag, data, option})
+	default:
+		return nil, fmt.Errorf("unknown forward mode %s", mode)
+	}
+
+	out := make([][]byte, len(msgs))
+	for i, m := range msgs {
+		b, err := msgpack.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+// send writes the encoded messages and, when chunk is set, waits for the
+// server to acknowledge it
+func (fc *forwardConn) send(msgs [][]byte, chunk string, ackTimeout time.Duration) error {
+	fc.conn.SetWriteDeadline(time.Now().Add(ackTimeout))
+	for _, m := range msgs {
+		if _, err := fc.conn.Write(m); err != nil {
+			return err
+		}
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	fc.conn.SetReadDeadline(time.Now().Add(ackTimeout))
+	defer fc.conn.SetReadDeadline(time.Time{})
+	// In message mode every message carries the chunk and is acked separately
+	for range msgs {
+		var resp map[string]interface{}
+		if err := fc.dec.Decode(&resp); err != nil {
+			return fmt.Errorf("failed to read ack: %w", err)
+		}
+		if ack, _ := resp["ack"].(string); ack != chunk {
+			return fmt.Errorf("ack mismatch: got %v, want %s", resp["ack"], chunk)
+		}
+	}
+	return nil
+}
+
+func (fc *forwardConn) Close() error {
+	return fc.conn.Close()
+}
@@ -0,0 +1,445 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+)
+
+var hecURL string
+var hecToken string
+var hecEndpoint string
+var hecType string
+var hecRFC string
+var hecLEEFVersion string
+var hecLEEFDelimiter string
+var hecIndex string
+var hecSourcetype string
+var hecSource string
+var hecBatchSize int
+var hecAck bool
+var hecAckInterval time.Duration
+var hecAckTimeout time.Duration
+var hecChannel string
+var hecGzip bool
+var hecTLSCA string
+var hecTLSInsecure bool
+var hecMaxRetries int
+var hecRate int
+var hecCount int
+
+// hecSourcetypes are the default sourcetypes for each event type
+var hecSourcetypes = map[string]string{
+	"json":       "_json",
+	"syslog":     "syslog",
+	"tms":        "fakedata:tms",
+	"firewall":   "fakedata:firewall",
+	"ids":        "fakedata:ids",
+	"multiline":  "fakedata:multiline",
+	"cef":        "cef",
+	"leef":       "leef",
+	"asa":        "cisco:asa",
+	"panos":      "pan:log",
+	"fortigate":  "fgt_log",
+	"checkpoint": "cp_log",
+	"srx":        "juniper:srx",
+	"eve":        "suricata",
+}
+
+// hecDefaultSourcetype returns the default sourcetype for an event type;
+// variants such as multiline-java share the sourcetype of their family
+func hecDefaultSourcetype(eventType string) string {
+	if sourcetype, ok := hecSourcetypes[eventType]; ok {
+		return sourcetype
+	}
+	family, _, _ := strings.Cut(eventType, "-")
+	return hecSourcetypes[family]
+}
+
+var hecCmd = &cobra.Command{
+	Use:   "hec",
+	Short: "Send fake events to a Splunk HTTP Event Collector",
+	Long: `Send fake events to a Splunk HTTP Event Collector (HEC).
+
+Endpoints:
+  event  /services/collector/event - JSON envelopes with metadata (default)
+  raw    /services/collector/raw   - newline-separated raw events
+
+The sourcetype defaults to one per event type (json=_json, syslog=syslog,
+tms=fakedata:tms, firewall=fakedata:firewall, ids=fakedata:ids,
+multiline-*=fakedata:multiline, cef-*=cef, leef-*=leef, asa=cisco:asa,
+panos=pan:log, fortigate=fgt_log, checkpoint=cp_log, srx=juniper:srx,
+eve-*=suricata).
+
+With --ack, requests carry a channel ID and the returned ack IDs are polled
+on /services/collector/ack until Splunk confirms indexing.
+
+For offline testing, run the local stand-in receiver:
+  fakedata hec-server --port 8088 --token 00000000-0000-0000-0000-000000000000
+
+Example:
+  fakedata hec --url http://localhost:8088 --token 00000000-0000-0000-0000-000000000000
+  fakedata hec --url https://splunk:8088 --token $TOKEN --tls-insecure --type firewall --index netfw
+  fakedata hec --url https://splunk:8088 --token $TOKEN --endpoint raw --type ids --batch-size 100 --gzip
+  fakedata hec --url https://splunk:8088 --token $TOKEN --ack --batch-size 50
+`,
+	RunE: runHEC,
+}
+
+func init() {
+	hecCmd.Flags().StringVar(&hecURL, "url", "https://localhost:8088", "HEC base URL")
+	hecCmd.Flags().StringVar(&hecToken, "token", "", "HEC token (required)")
+	hecCmd.Flags().StringVar(&hecEndpoint, "endpoint", "event", "HEC endpoint: event or raw")
+	hecCmd.Flags().StringVar(&hecType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	hecCmd.Flags().StringVar(&hecRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	hecCmd.Flags().StringVar(&hecLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	hecCmd.Flags().StringVar(&hecLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	hecCmd.Flags().StringVar(&hecIndex, "index", "", "Target index (default: token default)")
+	hecCmd.Flags().StringVar(&hecSourcetype, "sourcetype", "", "Sourcetype (default: per event type)")
+	hecCmd.Flags().StringVar(&hecSource, "source", "fakedata", "Source")
+	hecCmd.Flags().IntVar(&hecBatchSize, "batch-size", 1, "Events per request")
+	hecCmd.Flags().BoolVar(&hecAck, "ack", false, "Use indexer acknowledgement and poll for acks")
+	hecCmd.Flags().DurationVar(&hecAckInterval, "ack-interval", time.Second, "Interval between ack polls")
+	hecCmd.Flags().DurationVar(&hecAckTimeout, "ack-timeout", 30*time.Second, "How long to wait for outstanding acks on exit")
+	hecCmd.Flags().StringVar(&hecChannel, "channel", "", "Request channel GUID (default: random)")
+	hecCmd.Flags().BoolVar(&hecGzip, "gzip", false, "Gzip request bodies")
+	hecCmd.Flags().StringVar(&hecTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	hecCmd.Flags().BoolVar(&hecTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	hecCmd.Flags().IntVar(&hecMaxRetries, "max-retries", 3, "Retries for failed requests")
+	hecCmd.Flags().IntVar(&hecRate, "rate", 10, "Events per second")
+	hecCmd.Flags().IntVar(&hecCount, "count", 0, "Total events to send (0 = unlimited)")
+	hecCmd.MarkFlagRequired("token")
+}
+
+// hecEvent is the envelope sent to the event endpoint
+type hecEvent struct {
+	Time       float64         `json:"time"`
+	Host       string          `json:"host,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	Sourcetype string          `json:"sourcetype,omitempty"`
+	Index      string          `json:"index,omitempty"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// hecResponse is the body HEC returns for event, raw and ack requests
+type hecResponse struct {
+	Text  string          `json:"text"`
+	Code  int             `json:"code"`
+	AckID *int64          `json:"ackId,omitempty"`
+	Acks  map[string]bool `json:"acks,omitempty"`
+}
+
+// newHECChannel generates a random GUID for the request channel
+func newHECChannel() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// hecAckTracker polls outstanding ack IDs until they are confirmed
+type hecAckTracker struct {
+	mu       sync.Mutex
+	pending  map[int64]int // ack ID -> events in the request
+	acked    int
+	requests *hecRequester
+}
+
+func (t *hecAckTracker) Add(ackID int64, events int) {
+	t.mu.Lock()
+	t.pending[ackID] = events
+	t.mu.Unlock()
+}
+
+func (t *hecAckTracker) Outstanding() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// Poll asks HEC for the status of all outstanding ack IDs
+func (t *hecAckTracker) Poll() {
+	t.mu.Lock()
+	ids := make([]int64, 0, len(t.pending))
+	for id := range t.pending {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	body, _ := sonic.Marshal(map[string][]int64{"acks": ids})
+	resp, err := t.requests.Post("/services/collector/ack", body, "application/json")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error polling acks: %v\n", err)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, ok := range resp.Acks {
+		var ackID int64
+		if _, err := fmt.Sscan(id, &ackID); err != nil || !ok {
+			continue
+		}
+		if events, found := t.pending[ackID]; found {
+			t.acked += events
+			delete(t.pending, ackID)
+		}
+	}
+}
+
+// hecRequester posts to HEC endpoints with auth, channel and retries
+type hecRequester struct {
+	client  *http.Client
+	baseURL string
+	channel string
+	stats   *httpStats
+}
+
+func (r *hecRequester) Post(path string, body []byte, contentType string) (*hecResponse, error) {
+	encoding := ""
+	if hecGzip && path != "/services/collector/ack" {
+		compressed, err := gzipBytes(body)
+		if err != nil {
+			return nil, err
+		}
+		body, encoding = compressed, "gzip"
+	}
+
+	status, respBody, err := doHTTPWithRetry(r.client, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, r.baseURL+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Splunk "+hecToken)
+		req.Header.Set("Content-Type", contentType)
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		if r.channel != "" {
+			req.Header.Set("X-Splunk-Request-Channel", r.channel)
+		}
+		return req, nil
+	}, hecMaxRetries, r.stats)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &hecResponse{}
+	if len(respBody) > 0 {
+		if err := sonic.Unmarshal(respBody, resp); err != nil {
+			return nil, fmt.Errorf("status %d: invalid response: %s", status, strings.TrimSpace(string(respBody)))
+		}
+	}
+	if status != http.StatusOK {
+		return resp, fmt.Errorf("status %d: %s (code %d)", status, resp.Text, resp.Code)
+	}
+	return resp, nil
+}
+
+// hecBody encodes a batch of events for the configured endpoint
+func hecBody(events [][]byte, sourcetype string) []byte {
+	var buf bytes.Buffer
+	if hecEndpoint == "raw" {
+		for _, e := range events {
+			buf.Write(e)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes()
+	}
+
+	now := float64(time.Now().UnixMilli()) / 1000
+	for _, e := range events {
+		payload := json.RawMessage(e)
+		if !generators.IsJSONEventType(hecType) {
+			payload, _ = sonic.Marshal(string(e))
+		}
+		envelope, err := sonic.Marshal(hecEvent{
+			Time:       now,
+			Source:     hecSource,
+			Sourcetype: sourcetype,
+			Index:      hecIndex,
+			Event:      payload,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding event: %v\n", err)
+			continue
+		}
+		buf.Write(envelope)
+	}
+	return buf.Bytes()
+}
+
+func runHEC(cmd *cobra.Command, args []string) error {
+	if hecEndpoint != "event" && hecEndpoint != "raw" {
+		return fmt.Errorf("invalid endpoint: %s (must be event or raw)", hecEndpoint)
+	}
+	if !generators.ValidEventType(hecType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", hecType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(hecLEEFVersion, hecLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if hecBatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+
+	sourcetype := hecSourcetype
+	if sourcetype == "" {
+		sourcetype = hecDefaultSourcetype(hecType)
+	}
+
+	// Raw requests need a channel; acks need one on every request
+	channel := hecChannel
+	if channel == "" && (hecAck || hecEndpoint == "raw") {
+		channel = newHECChannel()
+	}
+
+	path := "/services/collector/event"
+	if hecEndpoint == "raw" {
+		query := url.Values{}
+		query.Set("channel", channel)
+		query.Set("sourcetype", sourcetype)
+		query.Set("source", hecSource)
+		if hecIndex != "" {
+			query.Set("index", hecIndex)
+		}
+		path = "/services/collector/raw?" + query.Encode()
+	}
+
+	tlsConfig, err := buildClientTLSConfig(hecTLSCA, "", "", "", hecTLSInsecure)
+	if err != nil {
+		return err
+	}
+	stats := newHTTPStats()
+	requester := &hecRequester{
+		client:  newHTTPClient(10*time.Second, tlsConfig, 1),
+		baseURL: strings.TrimRight(hecURL, "/"),
+		channel: channel,
+		stats:   stats,
+	}
+
+	var acks *hecAckTracker
+	if hecAck {
+		acks = &hecAckTracker{pending: make(map[int64]int), requests: requester}
+		go func() {
+			for range time.Tick(hecAckInterval) {
+				acks.Poll()
+			}
+		}()
+	}
+
+	fmt.Printf("Sending fake %s events to HEC %s endpoint at %s (%d events/s, batch size %d)\n",
+		hecType, hecEndpoint, hecURL, hecRate, hecBatchSize)
+	fmt.Printf("Sourcetype: %s, source: %s", sourcetype, hecSource)
+	if hecIndex != "" {
+		fmt.Printf(", index: %s", hecIndex)
+	}
+	fmt.Println()
+	if hecAck {
+		fmt.Printf("Indexer acknowledgement enabled (channel %s)\n", channel)
+	}
+	if hecCount > 0 {
+		fmt.Printf("Will send %d events total\n", hecCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(hecRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	generated := 0
+	startTime := time.Now()
+	opts := generators.Options{RFC: hecRFC, LEEF: leef}
+	batch := make([][]byte, 0, hecBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		resp, err := requester.Post(path, hecBody(batch, sourcetype), "application/json")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending: %v\n", err)
+			stats.recordFailed(len(batch))
+			batch = batch[:0]
+			return
+		}
+		if acks != nil && resp.AckID != nil {
+			acks.Add(*resp.AckID, len(batch))
+		}
+		before := sent
+		sent += len(batch)
+		batch = batch[:0]
+		if sent/1000 > before/1000 {
+			fmt.Printf("Sent %d events...\n", sent)
+		}
+	}
+	finish := func(format string) {
+		flush()
+		if acks != nil {
+			deadline := time.Now().Add(hecAckTimeout)
+			for acks.Outstanding() > 0 && time.Now().Before(deadline) {
+				acks.Poll()
+				time.Sleep(hecAckInterval)
+			}
+		}
+		fmt.Printf(format, sent, time.Since(startTime))
+		if acks != nil {
+			acks.mu.Lock()
+			fmt.Printf("Acknowledged %d events, %d requests unacknowledged\n", acks.acked, len(acks.pending))
+			acks.mu.Unlock()
+		}
+		stats.Print()
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d events in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(hecType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+			batch = append(batch, event)
+			generated++
+
+			if len(batch) >= hecBatchSize {
+				flush()
+			}
+
+			if hecCount > 0 && generated >= hecCount {
+				finish("Completed. Sent %d events in %v\n")
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,379 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var hecServerHost string
+var hecServerPort int
+var hecServerToken string
+var hecServerAck bool
+var hecServerAckDelay time.Duration
+var hecServerTLSCert string
+var hecServerTLSKey string
+var hecServerVerbose bool
+
+var hecServerCmd = &cobra.Command{
+	Use:   "hec-server",
+	Short: "Run a local Splunk HEC stand-in receiver",
+	Long: `Run a minimal Splunk HTTP Event Collector stand-in for offline testing.
+
+Implements the event, raw, ack and health endpoints with Splunk-compatible
+responses and error codes. Received events are counted per sourcetype and
+index, and summarised every 10 seconds while traffic arrives and on exit.
+
+With --ack, every request must carry a channel and receives an ack ID that
+reports as indexed after --ack-delay.
+
+Example:
+  # Terminal 1
+  fakedata hec-server --port 8088 --token 00000000-0000-0000-0000-000000000000 --ack
+
+  # Terminal 2
+  fakedata hec --url http://localhost:8088 --token 00000000-0000-0000-0000-000000000000 --ack
+`,
+	RunE: runHECServer,
+}
+
+func init() {
+	hecServerCmd.Flags().StringVar(&hecServerHost, "host", "0.0.0.0", "Address to listen on")
+	hecServerCmd.Flags().IntVar(&hecServerPort, "port", 8088, "Port to listen on")
+	hecServerCmd.Flags().StringVar(&hecServerToken, "token", "", "Required HEC token (empty = accept any)")
+	hecServerCmd.Flags().BoolVar(&hecServerAck, "ack", false, "Enable indexer acknowledgement")
+	hecServerCmd.Flags().DurationVar(&hecServerAckDelay, "ack-delay", time.Second, "Simulated indexing delay before acks are true")
+	hecServerCmd.Flags().StringVar(&hecServerTLSCert, "tls-cert", "", "Server certificate file (enables HTTPS)")
+	hecServerCmd.Flags().StringVar(&hecServerTLSKey, "tls-key", "", "Server private key file")
+	hecServerCmd.Flags().BoolVar(&hecServerVerbose, "verbose", false, "Print every received event")
+}
+
+// hecReceiver holds the state of the stand-in HEC server
+type hecReceiver struct {
+	mu       sync.Mutex
+	events   int
+	requests int
+	counts   map[string]int // "index/sourcetype" -> events
+	nextAck  map[string]int64
+	acks     map[string]map[int64]time.Time // channel -> ack ID -> indexed at
+}
+
+func newHECReceiver() *hecReceiver {
+	return &hecReceiver{
+		counts:  make(map[string]int),
+		nextAck: make(map[string]int64),
+		acks:    make(map[string]map[int64]time.Time),
+	}
+}
+
+func hecReply(w http.ResponseWriter, status int, resp hecResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// authorize checks the token and returns false after replying if it is invalid
+func (h *hecReceiver) authorize(w http.ResponseWriter, r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		hecReply(w, http.StatusUnauthorized, hecResponse{Text: "Token is required", Code: 2})
+		return false
+	}
+	scheme, token, _ := strings.Cut(auth, " ")
+	if scheme != "Splunk" {
+		hecReply(w, http.StatusUnauthorized, hecResponse{Text: "Invalid authorization", Code: 3})
+		return false
+	}
+	if hecServerToken != "" && token != hecServerToken {
+		hecReply(w, http.StatusForbidden, hecResponse{Text: "Invalid token", Code: 4})
+		return false
+	}
+	return true
+}
+
+// hecRequestChannel returns the request channel, from the header or query string
+func hecRequestChannel(r *http.Request) string {
+	if c := r.Header.Get("X-Splunk-Request-Channel"); c != "" {
+		return c
+	}
+	return r.URL.Query().Get("channel")
+}
+
+func hecReadBody(r *http.Request) ([]byte, error) {
+	var reader io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = zr
+	}
+	return io.ReadAll(reader)
+}
+
+// accept records received events and replies, issuing an ack ID if enabled
+func (h *hecReceiver) accept(w http.ResponseWriter, channel string, counts map[string]int, total int) {
+	h.mu.Lock()
+	h.requests++
+	h.events += total
+	for k, n := range counts {
+		h.counts[k] += n
+	}
+	resp := hecResponse{Text: "Success", Code: 0}
+	if hecServerAck {
+		id := h.nextAck[channel]
+		h.nextAck[channel] = id + 1
+		if h.acks[channel] == nil {
+			h.acks[channel] = make(map[int64]time.Time)
+		}
+		h.acks[channel][id] = time.Now().Add(hecServerAckDelay)
+		resp.AckID = &id
+	}
+	h.mu.Unlock()
+
+	hecReply(w, http.StatusOK, resp)
+}
+
+func (h *hecReceiver) handleEvent(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	channel := hecRequestChannel(r)
+	if hecServerAck && channel == "" {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "Data channel is missing", Code: 10})
+		return
+	}
+	body, err := hecReadBody(r)
+	if err != nil {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+		return
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "No data", Code: 5})
+		return
+	}
+
+	counts := make(map[string]int)
+	total := 0
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var ev struct {
+			Index      string          `json:"index"`
+			Sourcetype string          `json:"sourcetype"`
+			Event      json.RawMessage `json:"event"`
+		}
+		if err := dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			hecReply(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+			return
+		}
+		if len(ev.Event) == 0 {
+			hecReply(w, http.StatusBadRequest, hecResponse{Text: "Event field is required", Code: 12})
+			return
+		}
+		if string(ev.Event) == `""` {
+			hecReply(w, http.StatusBadRequest, hecResponse{Text: "Event field cannot be blank", Code: 13})
+			return
+		}
+		counts[hecCountKey(ev.Index, ev.Sourcetype)]++
+		total++
+		if hecServerVerbose {
+			fmt.Printf("[%s] %s\n", ev.Sourcetype, ev.Event)
+		}
+	}
+
+	h.accept(w, channel, counts, total)
+}
+
+func (h *hecReceiver) handleRaw(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	channel := hecRequestChannel(r)
+	if channel == "" {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "Data channel is missing", Code: 10})
+		return
+	}
+	body, err := hecReadBody(r)
+	if err != nil {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+		return
+	}
+
+	query := r.URL.Query()
+	key := hecCountKey(query.Get("index"), query.Get("sourcetype"))
+	total := 0
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		total++
+		if hecServerVerbose {
+			fmt.Printf("[%s] %s\n", query.Get("sourcetype"), scanner.Text())
+		}
+	}
+	if total == 0 {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "No data", Code: 5})
+		return
+	}
+
+	h.accept(w, channel, map[string]int{key: total}, total)
+}
+
+func (h *hecReceiver) handleAck(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+	if !hecServerAck {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "ACK is disabled", Code: 14})
+		return
+	}
+	channel := hecRequestChannel(r)
+	if channel == "" {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "Data channel is missing", Code: 10})
+		return
+	}
+
+	var req struct {
+		Acks []int64 `json:"acks"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		hecReply(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+		return
+	}
+
+	resp := hecResponse{Acks: make(map[string]bool, len(req.Acks))}
+	h.mu.Lock()
+	now := time.Now()
+	for _, id := range req.Acks {
+		indexedAt, ok := h.acks[channel][id]
+		done := ok && now.After(indexedAt)
+		resp.Acks[fmt.Sprint(id)] = done
+		if done {
+			// Splunk forgets an ack once it has been reported as true
+			delete(h.acks[channel], id)
+		}
+	}
+	h.mu.Unlock()
+
+	hecReply(w, http.StatusOK, resp)
+}
+
+func hecCountKey(index, sourcetype string) string {
+	if index == "" {
+		index = "main"
+	}
+	if sourcetype == "" {
+		sourcetype = "-"
+	}
+	return index + "/" + sourcetype
+}
+
+// Print writes a summary of received events
+func (h *hecReceiver) Print() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Printf("Received %d events in %d requests\n", h.events, h.requests)
+	keys := make([]string, 0, len(h.counts))
+	for k := range h.counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %s: %d\n", k, h.counts[k])
+	}
+}
+
+func runHECServer(cmd *cobra.Command, args []string) error {
+	if (hecServerTLSCert == "") != (hecServerTLSKey == "") {
+		return fmt.Errorf("both --tls-cert and --tls-key are required for HTTPS")
+	}
+
+	receiver := newHECReceiver()
+	mux := http.NewServeMux()
+	for _, p := range []string{"/services/collector", "/services/collector/event", "/services/collector/event/1.0"} {
+		mux.HandleFunc(p, receiver.handleEvent)
+	}
+	mux.HandleFunc("/services/collector/raw", receiver.handleRaw)
+	mux.HandleFunc("/services/collector/raw/1.0", receiver.handleRaw)
+	mux.HandleFunc("/services/collector/ack", receiver.handleAck)
+	mux.HandleFunc("/services/collector/health", func(w http.ResponseWriter, r *http.Request) {
+		hecReply(w, http.StatusOK, hecResponse{Text: "HEC is healthy", Code: 17})
+	})
+
+	addr := fmt.Sprintf("%s:%d", hecServerHost, hecServerPort)
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	errChan := make(chan error, 1)
+	go func() {
+		if hecServerTLSCert != "" {
+			errChan <- srv.ListenAndServeTLS(hecServerTLSCert, hecServerTLSKey)
+		} else {
+			errChan <- srv.ListenAndServe()
+		}
+	}()
+
+	scheme := "http"
+	if hecServerTLSCert != "" {
+		scheme = "https"
+	}
+	fmt.Printf("HEC stand-in listening on %s://%s\n", scheme, addr)
+	if hecServerToken != "" {
+		fmt.Println("Token authentication required")
+	}
+	if hecServerAck {
+		fmt.Printf("Indexer acknowledgement enabled (ack delay %v)\n", hecServerAckDelay)
+	}
+	fmt.Println("Press Ctrl+C to stop")
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	lastEvents := 0
+
+	for {
+		select {
+		case err := <-errChan:
+			return fmt.Errorf("HEC server failed: %w", err)
+		case <-ticker.C:
+			receiver.mu.Lock()
+			changed := receiver.events != lastEvents
+			lastEvents = receiver.events
+			receiver.mu.Unlock()
+			if changed {
+				receiver.Print()
+			}
+		case <-sigChan:
+			fmt.Println("\nStopping...")
+			srv.Close()
+			receiver.Print()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+)
+
+var httpURL string
+var httpMethod string
+var httpHeaders []string
+var httpFormat string
+var httpType string
+var httpRFC string
+var httpLEEFVersion string
+var httpLEEFDelimiter string
+var httpBatchSize int
+var httpUser string
+var httpPassword string
+var httpBearerToken string
+var httpGzip bool
+var httpTLSCA string
+var httpTLSCert string
+var httpTLSKey string
+var httpTLSInsecure bool
+var httpConcurrency int
+var httpMaxRetries int
+var httpTimeout time.Duration
+var httpRate int
+var httpCount int
+
+var httpCmd = &cobra.Command{
+	Use:   "http",
+	Short: "POST fake events to an HTTP/HTTPS endpoint",
+	Long: `Send fake events to an HTTP or HTTPS endpoint, one per request or batched.
+
+Body formats:
+  ndjson  One JSON document per line (default)
+  json    A JSON object, or a JSON array when --batch-size > 1
+  raw     Event lines as generated, newline-separated
+
+Non-JSON event types (syslog, tms, firewall, ids) are wrapped as
+{"message": "..."} in the ndjson and json formats.
+
+Requests answered with 429, 500, 502, 503 or 504 are retried, honouring any
+Retry-After header. A status code distribution is printed on exit.
+
+Example:
+  fakedata http --url http://localhost:8080/ingest --rate 100
+  fakedata http --url https://localhost:8443/ingest --batch-size 100 --gzip --tls-insecure
+  fakedata http --url http://localhost:8080/logs --type firewall --format raw --batch-size 50
+  fakedata http --url http://localhost:8080/ingest --bearer-token s3cret --header "X-Tenant: acme"
+  fakedata http --url http://localhost:8080/ingest --rate 10000 --batch-size 500 --concurrency 8
+`,
+	RunE: runHTTP,
+}
+
+func init() {
+	httpCmd.Flags().StringVar(&httpURL, "url", "", "Target URL (required)")
+	httpCmd.Flags().StringVar(&httpMethod, "method", "POST", "HTTP method")
+	httpCmd.Flags().StringArrayVar(&httpHeaders, "header", nil, "Extra header as \"Name: value\" (repeatable)")
+	httpCmd.Flags().StringVar(&httpFormat, "format", "ndjson", "Body format: ndjson, json, raw")
+	httpCmd.Flags().StringVar(&httpType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	httpCmd.Flags().StringVar(&httpRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	httpCmd.Flags().StringVar(&httpLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	httpCmd.Flags().StringVar(&httpLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	httpCmd.Flags().IntVar(&httpBatchSize, "batch-size", 1, "Events per request")
+	httpCmd.Flags().StringVar(&httpUser, "user", "", "Basic auth username")
+	httpCmd.Flags().StringVar(&httpPassword, "password", "", "Basic auth password")
+	httpCmd.Flags().StringVar(&httpBearerToken, "bearer-token", "", "Bearer token for the Authorization header")
+	httpCmd.Flags().BoolVar(&httpGzip, "gzip", false, "Gzip request bodies")
+	httpCmd.Flags().StringVar(&httpTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	httpCmd.Flags().StringVar(&httpTLSCert, "tls-cert", "", "Client certificate file (mTLS)")
+	httpCmd.Flags().StringVar(&httpTLSKey, "tls-key", "", "Client private key file (mTLS)")
+	httpCmd.Flags().BoolVar(&httpTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	httpCmd.Flags().IntVar(&httpConcurrency, "concurrency", 1, "Concurrent requests in flight")
+	httpCmd.Flags().IntVar(&httpMaxRetries, "max-retries", 3, "Retries for failed requests")
+	httpCmd.Flags().DurationVar(&httpTimeout, "timeout", 10*time.Second, "Request timeout")
+	httpCmd.Flags().IntVar(&httpRate, "rate", 10, "Events per second")
+	httpCmd.Flags().IntVar(&httpCount, "count", 0, "Total events to send (0 = unlimited)")
+	httpCmd.MarkFlagRequired("url")
+}
+
+// httpBody encodes a batch of events in the configured body format
+func httpBody(events [][]byte) ([]byte, string) {
+	var buf bytes.Buffer
+	switch httpFormat {
+	case "json":
+		if len(events) == 1 {
+			return jsonDocument(httpType, events[0]), "application/json"
+		}
+		buf.WriteByte('[')
+		for i, e := range events {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(jsonDocument(httpType, e))
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), "application/json"
+	case "raw":
+		for _, e := range events {
+			buf.Write(e)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), "text/plain"
+	default:
+		for _, e := range events {
+			buf.Write(jsonDocument(httpType, e))
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), "application/x-ndjson"
+	}
+}
+
+func runHTTP(cmd *cobra.Command, args []string) error {
+	if httpFormat != "ndjson" && httpFormat != "json" && httpFormat != "raw" {
+		return fmt.Errorf("invalid format: %s (must be ndjson, json or raw)", httpFormat)
+	}
+	if !generators.ValidEventType(httpType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", httpType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(httpLEEFVersion, httpLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if httpBatchSize < 1 || httpConcurrency < 1 {
+		return fmt.Errorf("--batch-size and --concurrency must be at least 1")
+	}
+	if httpBearerToken != "" && httpUser != "" {
+		return fmt.Errorf("only one of --bearer-token or --user may be set")
+	}
+
+	headers := make(http.Header)
+	for _, h := range httpHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q (expected \"Name: value\")", h)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	tlsConfig, err := buildClientTLSConfig(httpTLSCA, httpTLSCert, httpTLSKey, "", httpTLSInsecure)
+	if err != nil {
+		return err
+	}
+	client := newHTTPClient(httpTimeout, tlsConfig, httpConcurrency)
+	stats := newHTTPStats()
+
+	fmt.Printf("Sending fake %s events to %s %s at %d events/s (batch size %d, format %s)\n",
+		httpType, httpMethod, httpURL, httpRate, httpBatchSize, httpFormat)
+	if httpCount > 0 {
+		fmt.Printf("Will send %d events total\n", httpCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Workers send batches concurrently
+	var mu sync.Mutex
+	sent := 0
+	batches := make(chan [][]byte, httpConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < httpConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for events := range batches {
+				body, contentType := httpBody(events)
+				encoding := ""
+				if httpGzip {
+					compressed, err := gzipBytes(body)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error compressing: %v\n", err)
+						continue
+					}
+					body, encoding = compressed, "gzip"
+				}
+
+				status, respBody, err := doHTTPWithRetry(client, func() (*http.Request, error) {
+					req, err := http.NewRequest(httpMethod, httpURL, bytes.NewReader(body))
+					if err != nil {
+						return nil, err
+					}
+					for name, values := range headers {
+						req.Header[name] = values
+					}
+					if req.Header.Get("Content-Type") == "" {
+						req.Header.Set("Content-Type", contentType)
+					}
+					if encoding != "" {
+						req.Header.Set("Content-Encoding", encoding)
+					}
+					if httpBearerToken != "" {
+						req.Header.Set("Authorization", "Bearer "+httpBearerToken)
+					} else if httpUser != "" {
+						req.SetBasicAuth(httpUser, httpPassword)
+					}
+					return req, nil
+				}, httpMaxRetries, stats)
+
+				if err != nil || status < 200 || status >= 300 {
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error sending: %v\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Request failed with status %d: %s\n", status, strings.TrimSpace(string(respBody)))
+					}
+					stats.recordFailed(len(events))
+					continue
+				}
+
+				mu.Lock()
+				before := sent
+				sent += len(events)
+				if sent/1000 > before/1000 {
+					fmt.Printf("Sent %d events...\n", sent)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(httpRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	startTime := time.Now()
+	opts := generators.Options{RFC: httpRFC, LEEF: leef}
+	batch := make([][]byte, 0, httpBatchSize)
+	generated := 0
+
+	finish := func(format string) {
+		if len(batch) > 0 {
+			batches <- batch
+		}
+		close(batches)
+		wg.Wait()
+		fmt.Printf(format, sent, time.Since(startTime))
+		stats.Print()
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d events in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(httpType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+			batch = append(batch, event)
+			generated++
+
+			if len(batch) >= httpBatchSize {
+				batches <- batch
+				batch = make([][]byte, 0, httpBatchSize)
+			}
+
+			if httpCount > 0 && generated >= httpCount {
+				finish("Completed. Sent %d events in %v\n")
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytefreezer/fakedata/generators"
+)
+
+// httpStats tracks the distribution of HTTP status codes seen by a sink
+type httpStats struct {
+	mu     sync.Mutex
+	title  string
+	codes  map[int]int
+	errors int
+	failed int
+}
+
+func newHTTPStats() *httpStats {
+	return newHTTPStatsTitled("Status codes")
+}
+
+func newHTTPStatsTitled(title string) *httpStats {
+	return &httpStats{title: title, codes: make(map[int]int)}
+}
+
+func (s *httpStats) recordStatus(code int) {
+	s.mu.Lock()
+	s.codes[code]++
+	s.mu.Unlock()
+}
+
+func (s *httpStats) recordError() {
+	s.mu.Lock()
+	s.errors++
+	s.mu.Unlock()
+}
+
+func (s *httpStats) recordFailed(n int) {
+	s.mu.Lock()
+	s.failed += n
+	s.mu.Unlock()
+}
+
+// Print writes the status code distribution to stdout
+func (s *httpStats) Print() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	codes := make([]int, 0, len(s.codes))
+	for code := range s.codes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	fmt.Printf("%s:\n", s.title)
+	for _, code := range codes {
+		fmt.Printf("  %d: %d\n", code, s.codes[code])
+	}
+	if s.errors > 0 {
+		fmt.Printf("  connection errors: %d\n", s.errors)
+	}
+	if s.failed > 0 {
+		fmt.Printf("  dropped: %d\n", s.failed)
+	}
+}
+
+// newHTTPClient creates an HTTP client sized for the given concurrency
+func newHTTPClient(timeout time.Duration, tlsConfig *tls.Config, concurrency int) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.MaxIdleConnsPerHost = concurrency
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// httpRetryable reports whether a request with this status should be retried
+func httpRetryable(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// httpRetryDelay honours Retry-After (seconds or HTTP date) and otherwise
+// backs off exponentially, capped at 30 seconds
+func httpRetryDelay(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if v := resp.Header.Get("Retry-After"); v != "" {
+			if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+				return time.Duration(secs) * time.Second
+			}
+			if t, err := http.ParseTime(v); err == nil {
+				if d := time.Until(t); d > 0 {
+					return d
+				}
+				return 0
+			}
+		}
+	}
+	d := time.Duration(1<<attempt) * 100 * time.Millisecond
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d
+}
+
+// doHTTPWithRetry sends the request built by newReq, retrying connection
+// errors and retryable statuses. It returns the final status and body.
+func doHTTPWithRetry(client *http.Client, newReq func() (*http.Request, error), maxRetries int, stats *httpStats) (int, []byte, error) {
+	return doHTTPWithRetryIf(client, newReq, maxRetries, stats, httpRetryable)
+}
+
+// doHTTPWithRetryIf is doHTTPWithRetry with the set of retryable statuses
+// given by retryable
+func doHTTPWithRetryIf(client *http.Client, newReq func() (*http.Request, error), maxRetries int, stats *httpStats, retryable func(int) bool) (int, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			stats.recordError()
+			if attempt >= maxRetries {
+				return 0, nil, err
+			}
+			time.Sleep(httpRetryDelay(nil, attempt))
+			continue
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		stats.recordStatus(resp.StatusCode)
+
+		if retryable(resp.StatusCode) && attempt < maxRetries {
+			time.Sleep(httpRetryDelay(resp, attempt))
+			continue
+		}
+		return resp.StatusCode, body, nil
+	}
+}
+
+// gzipBytes compresses data with gzip
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonDocument returns a generated event as a single-line JSON document,
+// wrapping non-JSON event types as {"message": "..."}
+func jsonDocument(eventType string, event []byte) []byte {
+	if generators.IsJSONEventType(eventType) {
+		// Pretty-printed events such as multiline-json span several lines
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, event); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding event: %v\n", err)
+			return nil
+		}
+		return buf.Bytes()
+	}
+	doc, err := sonic.Marshal(map[string]string{"message": string(event)})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding event: %v\n", err)
+		return nil
+	}
+	return doc
+}
@@ -6,15 +6,19 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/bytedance/sonic"
 	"github.com/bytefreezer/fakedata/generators"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +28,23 @@ var kinesisRegion string
 var kinesisEndpoint string
 var kinesisRate int
 var kinesisCount int
+var kinesisBatchSize int
+var kinesisMaxRetries int
+var kinesisAggregate bool
+var kinesisAggregateCount int
+var kinesisPartitionKeyField string
+var kinesisHashKeyField string
+var kinesisHotShardRatio float64
+var kinesisShardLimit bool
+
+// Kinesis quotas
+const (
+	kinesisMaxBatchRecords    = 500
+	kinesisMaxBatchBytes      = 5 * 1024 * 1024
+	kinesisMaxRecordBytes     = 1024 * 1024
+	kinesisShardRecordsPerSec = 1000
+	kinesisShardBytesPerSec   = 1024 * 1024
+)
 
 var kinesisCmd = &cobra.Command{
 	Use:   "kinesis",
@@ -40,6 +61,19 @@ Example:
 
   # AWS
   fakedata kinesis --stream my-stream --region us-east-1
+
+  # PutRecords batches of 500, KPL-aggregated 100 events per record
+  fakedata kinesis --stream my-stream --batch-size 500 --aggregate --aggregate-count 100
+
+  # Partition by source IP, with 80% of records forced onto one hot shard
+  fakedata kinesis --stream my-stream --partition-key-field source_ip --hot-shard-ratio 0.8
+
+  # Stay within per-shard quotas (1000 records/s, 1 MiB/s)
+  fakedata kinesis --stream my-stream --rate 5000 --batch-size 500 --shard-limit
+
+Partition keys default to random "pk-N" values. --hash-key-field sets an
+ExplicitHashKey from the MD5 of the field value, pinning records with the same
+value to the same shard. A per-shard record distribution is printed on exit.
 `,
 	RunE: runKinesis,
 }
@@ -50,10 +84,183 @@ func init() {
 	kinesisCmd.Flags().StringVar(&kinesisEndpoint, "endpoint", "", "Custom endpoint URL (for LocalStack)")
 	kinesisCmd.Flags().IntVar(&kinesisRate, "rate", 10, "Records per second")
 	kinesisCmd.Flags().IntVar(&kinesisCount, "count", 0, "Total records to send (0 = unlimited)")
+	kinesisCmd.Flags().IntVar(&kinesisBatchSize, "batch-size", 1, "Records per PutRecords call (1-500)")
+	kinesisCmd.Flags().IntVar(&kinesisMaxRetries, "max-retries", 3, "Retries for failed records")
+	kinesisCmd.Flags().BoolVar(&kinesisAggregate, "aggregate", false, "Pack events into KPL-aggregated records")
+	kinesisCmd.Flags().IntVar(&kinesisAggregateCount, "aggregate-count", 100, "Events per aggregated record")
+	kinesisCmd.Flags().StringVar(&kinesisPartitionKeyField, "partition-key-field", "", "Event field used as partition key (default: random)")
+	kinesisCmd.Flags().StringVar(&kinesisHashKeyField, "hash-key-field", "", "Event field hashed into ExplicitHashKey")
+	kinesisCmd.Flags().Float64Var(&kinesisHotShardRatio, "hot-shard-ratio", 0, "Fraction of records sent to a single hot partition key (0-1)")
+	kinesisCmd.Flags().BoolVar(&kinesisShardLimit, "shard-limit", false, "Throttle to per-shard quotas (1000 records/s, 1 MiB/s)")
 	kinesisCmd.MarkFlagRequired("stream")
 }
 
+// kinesisEntry is a Kinesis record along with the number of events it carries
+type kinesisEntry struct {
+	entry types.PutRecordsRequestEntry
+	count int
+}
+
+// kinesisKeys picks the partition key and explicit hash key for an event
+func kinesisKeys(event map[string]interface{}) (string, string) {
+	if kinesisHotShardRatio > 0 && rand.Float64() < kinesisHotShardRatio {
+		return "pk-hot", ""
+	}
+
+	partitionKey := eventField(event, kinesisPartitionKeyField)
+	if partitionKey == "" {
+		// Use random partition key for distribution across shards
+		partitionKey = fmt.Sprintf("pk-%d", rand.Intn(1000))
+	}
+
+	var hashKey string
+	if v := eventField(event, kinesisHashKeyField); v != "" {
+		hashKey = kinesisHashKey(v).String()
+	}
+
+	return partitionKey, hashKey
+}
+
+// kinesisShardWindow tracks usage of one shard in the current second
+type kinesisShardWindow struct {
+	id          string
+	start, end  *big.Int
+	windowStart time.Time
+	records     int
+	bytes       int
+}
+
+// kinesisShardLimiter keeps per-shard writes within Kinesis quotas
+type kinesisShardLimiter struct {
+	shards []*kinesisShardWindow
+}
+
+// newKinesisShardLimiter loads the open shards of a stream and their hash key ranges
+func newKinesisShardLimiter(ctx context.Context, client *kinesis.Client, stream string) (*kinesisShardLimiter, error) {
+	l := &kinesisShardLimiter{}
+	input := &kinesis.ListShardsInput{StreamName: aws.String(stream)}
+	for {
+		out, err := client.ListShards(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list shards: %w", err)
+		}
+		for _, shard := range out.Shards {
+			// Closed shards no longer accept writes
+			if shard.SequenceNumberRange != nil && shard.SequenceNumberRange.EndingSequenceNumber != nil {
+				continue
+			}
+			start, ok1 := new(big.Int).SetString(aws.ToString(shard.HashKeyRange.StartingHashKey), 10)
+			end, ok2 := new(big.Int).SetString(aws.ToString(shard.HashKeyRange.EndingHashKey), 10)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("invalid hash key range for shard %s", aws.ToString(shard.ShardId))
+			}
+			l.shards = append(l.shards, &kinesisShardWindow{id: aws.ToString(shard.ShardId), start: start, end: end})
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input = &kinesis.ListShardsInput{NextToken: out.NextToken}
+	}
+	if len(l.shards) == 0 {
+		return nil, fmt.Errorf("stream %s has no open shards", stream)
+	}
+	return l, nil
+}
+
+// Wait blocks until the shard owning the record has quota left for it
+func (l *kinesisShardLimiter) Wait(entry types.PutRecordsRequestEntry) {
+	var hash *big.Int
+	if entry.ExplicitHashKey != nil {
+		hash, _ = new(big.Int).SetString(*entry.ExplicitHashKey, 10)
+	}
+	if hash == nil {
+		hash = kinesisHashKey(aws.ToString(entry.PartitionKey))
+	}
+
+	for _, shard := range l.shards {
+		if hash.Cmp(shard.start) < 0 || hash.Cmp(shard.end) > 0 {
+			continue
+		}
+		size := len(entry.Data) + len(aws.ToString(entry.PartitionKey))
+		if time.Since(shard.windowStart) >= time.Second {
+			shard.windowStart = time.Now()
+			shard.records, shard.bytes = 0, 0
+		}
+		if shard.records+1 > kinesisShardRecordsPerSec || shard.bytes+size > kinesisShardBytesPerSec {
+			time.Sleep(time.Until(shard.windowStart.Add(time.Second)))
+			shard.windowStart = time.Now()
+			shard.records, shard.bytes = 0, 0
+		}
+		shard.records++
+		shard.bytes += size
+		return
+	}
+}
+
+// kinesisPutRecords sends entries with PutRecords, retrying the records that
+// failed. Returns the number of events delivered and counts them per shard.
+func kinesisPutRecords(ctx context.Context, client *kinesis.Client, stream string, entries []kinesisEntry, shardCounts map[string]int) int {
+	delivered := 0
+	pending := entries
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * 100 * time.Millisecond)
+		}
+
+		records := make([]types.PutRecordsRequestEntry, len(pending))
+		for i, e := range pending {
+			records[i] = e.entry
+		}
+
+		out, err := client.PutRecords(ctx, &kinesis.PutRecordsInput{
+			StreamName: aws.String(stream),
+			Records:    records,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error putting records: %v\n", err)
+			if attempt >= kinesisMaxRetries {
+				return delivered
+			}
+			continue
+		}
+
+		var retry []kinesisEntry
+		var lastErr string
+		for i, r := range out.Records {
+			if r.ErrorCode != nil {
+				retry = append(retry, pending[i])
+				lastErr = aws.ToString(r.ErrorCode)
+				continue
+			}
+			delivered += pending[i].count
+			shardCounts[aws.ToString(r.ShardId)] += pending[i].count
+		}
+
+		if len(retry) > 0 {
+			if attempt >= kinesisMaxRetries {
+				fmt.Fprintf(os.Stderr, "Giving up on %d records after %d retries (%s)\n", len(retry), kinesisMaxRetries, lastErr)
+				return delivered
+			}
+			fmt.Fprintf(os.Stderr, "%d records failed (%s), retrying\n", len(retry), lastErr)
+		}
+		pending = retry
+	}
+
+	return delivered
+}
+
 func runKinesis(cmd *cobra.Command, args []string) error {
+	if kinesisBatchSize < 1 || kinesisBatchSize > kinesisMaxBatchRecords {
+		return fmt.Errorf("invalid batch size: %d (must be 1-%d)", kinesisBatchSize, kinesisMaxBatchRecords)
+	}
+	if kinesisAggregateCount < 1 {
+		return fmt.Errorf("invalid aggregate count: %d (must be at least 1)", kinesisAggregateCount)
+	}
+	if kinesisHotShardRatio < 0 || kinesisHotShardRatio > 1 {
+		return fmt.Errorf("invalid hot shard ratio: %v (must be 0-1)", kinesisHotShardRatio)
+	}
+
 	ctx := context.Background()
 
 	// Load AWS config
@@ -89,7 +296,19 @@ func runKinesis(cmd *cobra.Command, args []string) error {
 		client = kinesis.NewFromConfig(cfg)
 	}
 
-	fmt.Printf("Putting fake JSON records to Kinesis stream '%s' at %d rec/s\n", kinesisStream, kinesisRate)
+	var limiter *kinesisShardLimiter
+	if kinesisShardLimit {
+		limiter, err = newKinesisShardLimiter(ctx, client, kinesisStream)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Throttling to per-shard quotas across %d shards\n", len(limiter.shards))
+	}
+
+	fmt.Printf("Putting fake JSON records to Kinesis stream '%s' at %d rec/s (batch size %d)\n", kinesisStream, kinesisRate, kinesisBatchSize)
+	if kinesisAggregate {
+		fmt.Printf("Aggregating up to %d events per record (KPL format)\n", kinesisAggregateCount)
+	}
 	if kinesisCount > 0 {
 		fmt.Printf("Will send %d records total\n", kinesisCount)
 	} else {
@@ -106,41 +325,105 @@ func runKinesis(cmd *cobra.Command, args []string) error {
 
 	sent := 0
 	startTime := time.Now()
+	shardCounts := make(map[string]int)
+
+	var agg kplAggregator
+	var pending []kinesisEntry
+	pendingBytes := 0
 
+	enqueue := func(e kinesisEntry) {
+		if limiter != nil {
+			limiter.Wait(e.entry)
+		}
+		pending = append(pending, e)
+		pendingBytes += len(e.entry.Data) + len(aws.ToString(e.entry.PartitionKey))
+	}
+	flushAggregate := func() {
+		data, pk, ehk, count := agg.Flush()
+		if count == 0 {
+			return
+		}
+		entry := types.PutRecordsRequestEntry{Data: data, PartitionKey: aws.String(pk)}
+		if ehk != "" {
+			entry.ExplicitHashKey = aws.String(ehk)
+		}
+		enqueue(kinesisEntry{entry: entry, count: count})
+	}
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		before := sent
+		sent += kinesisPutRecords(ctx, client, kinesisStream, pending, shardCounts)
+		pending = pending[:0]
+		pendingBytes = 0
+		if sent/1000 > before/1000 {
+			fmt.Printf("Sent %d records...\n", sent)
+		}
+	}
+	finish := func(format string) {
+		flushAggregate()
+		flush()
+		fmt.Printf(format, sent, time.Since(startTime))
+		printKinesisShardCounts(shardCounts)
+	}
+
+	generated := 0
 	for {
 		select {
 		case <-sigChan:
-			fmt.Printf("\nStopped. Sent %d records in %v\n", sent, time.Since(startTime))
+			finish("\nStopped. Sent %d records in %v\n")
 			return nil
 		case <-ticker.C:
-			event, err := generators.GenerateJSONEvent()
+			event := generators.NewJSONEvent()
+			data, err := sonic.Marshal(event)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
 				continue
 			}
+			generated++
 
-			// Use random partition key for distribution across shards
-			partitionKey := fmt.Sprintf("pk-%d", rand.Intn(1000))
-
-			_, err = client.PutRecord(ctx, &kinesis.PutRecordInput{
-				StreamName:   aws.String(kinesisStream),
-				Data:         event,
-				PartitionKey: aws.String(partitionKey),
-			})
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error putting record: %v\n", err)
-				continue
+			partitionKey, hashKey := kinesisKeys(event)
+			if kinesisAggregate {
+				if agg.Size()+len(data) > kinesisMaxRecordBytes-1024 {
+					flushAggregate()
+				}
+				agg.Add(kplUserRecord{partitionKey: partitionKey, explicitHashKey: hashKey, data: data})
+				if agg.Len() >= kinesisAggregateCount {
+					flushAggregate()
+				}
+			} else {
+				entry := types.PutRecordsRequestEntry{Data: data, PartitionKey: aws.String(partitionKey)}
+				if hashKey != "" {
+					entry.ExplicitHashKey = aws.String(hashKey)
+				}
+				enqueue(kinesisEntry{entry: entry, count: 1})
 			}
 
-			sent++
-			if sent%1000 == 0 {
-				fmt.Printf("Sent %d records...\n", sent)
+			if len(pending) >= kinesisBatchSize || pendingBytes >= kinesisMaxBatchBytes-kinesisMaxRecordBytes {
+				flush()
 			}
 
-			if kinesisCount > 0 && sent >= kinesisCount {
-				fmt.Printf("Completed. Sent %d records in %v\n", sent, time.Since(startTime))
+			if kinesisCount > 0 && generated >= kinesisCount {
+				finish("Completed. Sent %d records in %v\n")
 				return nil
 			}
 		}
 	}
 }
+
+// printKinesisShardCounts prints how delivered events were spread across shards
+func printKinesisShardCounts(shardCounts map[string]int) {
+	if len(shardCounts) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(shardCounts))
+	for id := range shardCounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	fmt.Println("Records per shard:")
+	for _, id := range ids {
+		fmt.Printf("  %s: %d\n", id, shardCounts[id])
+	}
+}
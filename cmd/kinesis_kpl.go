@@ -0,0 +1,127 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"crypto/md5"
+	"math/big"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// kplMagic prefixes every KPL-aggregated Kinesis record
+var kplMagic = []byte{0xF3, 0x89, 0x9A, 0xC2}
+
+// kplUserRecord is a single event packed into an aggregated record
+type kplUserRecord struct {
+	partitionKey    string
+	explicitHashKey string
+	data            []byte
+}
+
+// kplAggregator packs user records into the KPL aggregated record format:
+// magic + AggregatedRecord protobuf + MD5 of the protobuf.
+//
+//	message AggregatedRecord {
+//	  repeated string partition_key_table     = 1;
+//	  repeated string explicit_hash_key_table = 2;
+//	  repeated Record records                 = 3;
+//	}
+//	message Record {
+//	  required uint64 partition_key_index     = 1;
+//	  optional uint64 explicit_hash_key_index = 2;
+//	  required bytes  data                    = 3;
+//	}
+type kplAggregator struct {
+	records []kplUserRecord
+	size    int
+}
+
+// Add appends a user record to the aggregate
+func (a *kplAggregator) Add(r kplUserRecord) {
+	a.records = append(a.records, r)
+	a.size += len(r.data) + len(r.partitionKey) + len(r.explicitHashKey) + 16
+}
+
+// Len returns the number of pending user records
+func (a *kplAggregator) Len() int {
+	return len(a.records)
+}
+
+// Size returns an estimate of the encoded aggregate size in bytes
+func (a *kplAggregator) Size() int {
+	return a.size
+}
+
+// Flush encodes the pending user records and resets the aggregator. The
+// returned keys are those of the first user record, as the KPL does.
+func (a *kplAggregator) Flush() (data []byte, partitionKey, explicitHashKey string, count int) {
+	if len(a.records) == 0 {
+		return nil, "", "", 0
+	}
+
+	var pkTable, ehkTable []string
+	pkIndex := make(map[string]uint64)
+	ehkIndex := make(map[string]uint64)
+
+	var body []byte
+	for _, r := range a.records {
+		pk, ok := pkIndex[r.partitionKey]
+		if !ok {
+			pk = uint64(len(pkTable))
+			pkIndex[r.partitionKey] = pk
+			pkTable = append(pkTable, r.partitionKey)
+		}
+
+		var rec []byte
+		rec = protowire.AppendTag(rec, 1, protowire.VarintType)
+		rec = protowire.AppendVarint(rec, pk)
+		if r.explicitHashKey != "" {
+			ehk, ok := ehkIndex[r.explicitHashKey]
+			if !ok {
+				ehk = uint64(len(ehkTable))
+				ehkIndex[r.explicitHashKey] = ehk
+				ehkTable = append(ehkTable, r.explicitHashKey)
+			}
+			rec = protowire.AppendTag(rec, 2, protowire.VarintType)
+			rec = protowire.AppendVarint(rec, ehk)
+		}
+		rec = protowire.AppendTag(rec, 3, protowire.BytesType)
+		rec = protowire.AppendBytes(rec, r.data)
+
+		body = protowire.AppendTag(body, 3, protowire.BytesType)
+		body = protowire.AppendBytes(body, rec)
+	}
+
+	var msg []byte
+	for _, k := range pkTable {
+		msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+		msg = protowire.AppendString(msg, k)
+	}
+	for _, k := range ehkTable {
+		msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+		msg = protowire.AppendString(msg, k)
+	}
+	msg = append(msg, body...)
+
+	sum := md5.Sum(msg)
+	data = make([]byte, 0, len(kplMagic)+len(msg)+len(sum))
+	data = append(data, kplMagic...)
+	data = append(data, msg...)
+	data = append(data, sum[:]...)
+
+	first := a.records[0]
+	count = len(a.records)
+	a.records = a.records[:0]
+	a.size = 0
+
+	return data, first.partitionKey, first.explicitHashKey, count
+}
+
+// kinesisHashKey returns the 128-bit hash Kinesis uses to map a partition key
+// to a shard, as an unsigned integer
+func kinesisHashKey(partitionKey string) *big.Int {
+	sum := md5.Sum([]byte(partitionKey))
+	return new(big.Int).SetBytes(sum[:])
+}
@@ -0,0 +1,157 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// kplDecodedRecord is a user record read back from an aggregate
+type kplDecodedRecord struct {
+	partitionKey    string
+	explicitHashKey string
+	data            string
+}
+
+// decodeKPL parses an aggregated record, checking the magic and MD5
+func decodeKPL(t *testing.T, data []byte) []kplDecodedRecord {
+	t.Helper()
+	if !bytes.HasPrefix(data, kplMagic) {
+		t.Fatalf("aggregate does not start with the KPL magic: % x", data[:4])
+	}
+	if len(data) < len(kplMagic)+md5.Size {
+		t.Fatalf("aggregate too short: %d bytes", len(data))
+	}
+	msg := data[len(kplMagic) : len(data)-md5.Size]
+	if sum := md5.Sum(msg); !bytes.Equal(sum[:], data[len(data)-md5.Size:]) {
+		t.Fatalf("MD5 trailer %x does not match the protobuf (%x)", data[len(data)-md5.Size:], sum)
+	}
+
+	var pkTable, ehkTable []string
+	var records [][]byte
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 || typ != protowire.BytesType {
+			t.Fatalf("bad AggregatedRecord tag: field %d type %d", num, typ)
+		}
+		msg = msg[n:]
+		v, n := protowire.ConsumeBytes(msg)
+		if n < 0 {
+			t.Fatalf("bad AggregatedRecord field %d", num)
+		}
+		msg = msg[n:]
+		switch num {
+		case 1:
+			pkTable = append(pkTable, string(v))
+		case 2:
+			ehkTable = append(ehkTable, string(v))
+		case 3:
+			records = append(records, v)
+		default:
+			t.Fatalf("unexpected AggregatedRecord field %d", num)
+		}
+	}
+
+	var out []kplDecodedRecord
+	for _, rec := range records {
+		var r kplDecodedRecord
+		hasPK, hasData := false, false
+		for len(rec) > 0 {
+			num, typ, n := protowire.ConsumeTag(rec)
+			if n < 0 {
+				t.Fatal("bad Record tag")
+			}
+			rec = rec[n:]
+			switch {
+			case (num == 1 || num == 2) && typ == protowire.VarintType:
+				idx, n := protowire.ConsumeVarint(rec)
+				if n < 0 {
+					t.Fatalf("bad Record index field %d", num)
+				}
+				rec = rec[n:]
+				if num == 1 {
+					if idx >= uint64(len(pkTable)) {
+						t.Fatalf("partition_key_index %d out of range (%d keys)", idx, len(pkTable))
+					}
+					r.partitionKey, hasPK = pkTable[idx], true
+				} else {
+					if idx >= uint64(len(ehkTable)) {
+						t.Fatalf("explicit_hash_key_index %d out of range (%d keys)", idx, len(ehkTable))
+					}
+					r.explicitHashKey = ehkTable[idx]
+				}
+			case num == 3 && typ == protowire.BytesType:
+				v, n := protowire.ConsumeBytes(rec)
+				if n < 0 {
+					t.Fatal("bad Record data")
+				}
+				rec = rec[n:]
+				r.data, hasData = string(v), true
+			default:
+				t.Fatalf("unexpected Record field %d type %d", num, typ)
+			}
+		}
+		if !hasPK || !hasData {
+			t.Fatalf("Record is missing required fields: %+v", r)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestKPLAggregatorRoundTrip(t *testing.T) {
+	in := []kplUserRecord{
+		{partitionKey: "alice", data: []byte(`{"n":1}`)},
+		{partitionKey: "bob", explicitHashKey: "170141183460469231731687303715884105728", data: []byte(`{"n":2}`)},
+		{partitionKey: "alice", explicitHashKey: "170141183460469231731687303715884105728", data: []byte(`{"n":3}`)},
+		{partitionKey: "carol", explicitHashKey: "1", data: []byte{}},
+	}
+
+	var a kplAggregator
+	for _, r := range in {
+		a.Add(r)
+	}
+	if a.Len() != len(in) {
+		t.Fatalf("Len() = %d, want %d", a.Len(), len(in))
+	}
+
+	data, pk, ehk, count := a.Flush()
+	if count != len(in) {
+		t.Errorf("count = %d, want %d", count, len(in))
+	}
+	if pk != "alice" || ehk != "" {
+		t.Errorf("keys = %q, %q, want those of the first record", pk, ehk)
+	}
+
+	got := decodeKPL(t, data)
+	if len(got) != len(in) {
+		t.Fatalf("decoded %d records, want %d", len(got), len(in))
+	}
+	for i, r := range in {
+		want := kplDecodedRecord{r.partitionKey, r.explicitHashKey, string(r.data)}
+		if got[i] != want {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want)
+		}
+	}
+
+	// Repeated keys share one table entry
+	if n := bytes.Count(data, []byte("alice")); n != 1 {
+		t.Errorf("partition key table holds %q %d times, want 1", "alice", n)
+	}
+	if n := bytes.Count(data, []byte("170141183460469231731687303715884105728")); n != 1 {
+		t.Errorf("explicit hash key table holds the shared key %d times, want 1", n)
+	}
+
+	// Flushing resets the aggregator
+	if a.Len() != 0 || a.Size() != 0 {
+		t.Errorf("after Flush Len() = %d, Size() = %d, want 0, 0", a.Len(), a.Size())
+	}
+	if data, _, _, count := a.Flush(); data != nil || count != 0 {
+		t.Errorf("empty Flush() = %d bytes, count %d, want nothing", len(data), count)
+	}
+}
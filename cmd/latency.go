@@ -0,0 +1,61 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySamples bounds the window percentiles are computed over
+const latencySamples = 10000
+
+// latencyStats tracks acknowledgement latencies. Percentiles cover the most
+// recent latencySamples observations; count, average and max cover all.
+type latencyStats struct {
+	mu      sync.Mutex
+	count   int
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration
+	next    int
+}
+
+func (l *latencyStats) record(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.count++
+	l.total += d
+	if d > l.max {
+		l.max = d
+	}
+	if len(l.samples) < latencySamples {
+		l.samples = append(l.samples, d)
+	} else {
+		l.samples[l.next] = d
+		l.next = (l.next + 1) % latencySamples
+	}
+}
+
+// Print writes a one-line latency summary to stdout
+func (l *latencyStats) Print(title string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count == 0 {
+		return
+	}
+	sorted := append([]time.Duration(nil), l.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	pct := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	fmt.Printf("%s: n=%d avg=%v p50=%v p99=%v max=%v\n", title, l.count,
+		(l.total / time.Duration(l.count)).Round(time.Microsecond),
+		pct(0.50).Round(time.Microsecond), pct(0.99).Round(time.Microsecond),
+		l.max.Round(time.Microsecond))
+}
@@ -0,0 +1,383 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/golang/snappy"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var lokiURL string
+var lokiType string
+var lokiRFC string
+var lokiLEEFVersion string
+var lokiLEEFDelimiter string
+var lokiEncoding string
+var lokiLabels string
+var lokiStaticLabels string
+var lokiCardinality int
+var lokiBatchSize int
+var lokiTenant string
+var lokiUser string
+var lokiPassword string
+var lokiTLSCA string
+var lokiTLSInsecure bool
+var lokiMaxRetries int
+var lokiRate int
+var lokiCount int
+
+var lokiCmd = &cobra.Command{
+	Use:   "loki",
+	Short: "Push fake log lines to Grafana Loki",
+	Long: `Push fake log lines to Grafana Loki through /loki/api/v1/push.
+
+Encodings:
+  protobuf  Snappy-compressed protobuf PushRequest (default, as Promtail sends)
+  json      JSON streams/values body
+
+Stream labels are derived from each event with --labels:
+  type      The event type (json, syslog, tms, firewall, ids, multiline-*,
+            cef-*, leef-*, asa, panos, fortigate, checkpoint, srx, eve-*)
+  hostname  The syslog HOSTNAME (syslog types only)
+  process   The syslog APP-NAME, or the "process" field of JSON events
+  severity  The syslog severity name (syslog types only)
+
+--static-labels adds fixed labels, and --cardinality N adds a "stream" label
+with N distinct values, multiplying the number of streams to exercise Loki's
+per-tenant stream limits.
+
+Example:
+  fakedata loki --url http://localhost:3100 --type firewall --rate 100
+  fakedata loki --url http://localhost:3100 --type syslog --labels hostname,process --encoding json
+  fakedata loki --url http://localhost:3100 --type ids --cardinality 5000 --tenant team-a
+`,
+	RunE: runLoki,
+}
+
+func init() {
+	lokiCmd.Flags().StringVar(&lokiURL, "url", "http://localhost:3100", "Loki base URL")
+	lokiCmd.Flags().StringVar(&lokiType, "type", "syslog", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	lokiCmd.Flags().StringVar(&lokiRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	lokiCmd.Flags().StringVar(&lokiLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	lokiCmd.Flags().StringVar(&lokiLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	lokiCmd.Flags().StringVar(&lokiEncoding, "encoding", "protobuf", "Push encoding: protobuf or json")
+	lokiCmd.Flags().StringVar(&lokiLabels, "labels", "type,hostname,process", "Labels derived from events, comma-separated")
+	lokiCmd.Flags().StringVar(&lokiStaticLabels, "static-labels", "job=fakedata", "Fixed labels, e.g. job=fakedata,env=test")
+	lokiCmd.Flags().IntVar(&lokiCardinality, "cardinality", 0, "Distinct values of an extra \"stream\" label (0 = off)")
+	lokiCmd.Flags().IntVar(&lokiBatchSize, "batch-size", 100, "Log lines per push request")
+	lokiCmd.Flags().StringVar(&lokiTenant, "tenant", "", "Tenant ID sent as X-Scope-OrgID")
+	lokiCmd.Flags().StringVar(&lokiUser, "user", "", "Basic auth username")
+	lokiCmd.Flags().StringVar(&lokiPassword, "password", "", "Basic auth password")
+	lokiCmd.Flags().StringVar(&lokiTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	lokiCmd.Flags().BoolVar(&lokiTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	lokiCmd.Flags().IntVar(&lokiMaxRetries, "max-retries", 3, "Retries for failed pushes")
+	lokiCmd.Flags().IntVar(&lokiRate, "rate", 10, "Log lines per second")
+	lokiCmd.Flags().IntVar(&lokiCount, "count", 0, "Total log lines to send (0 = unlimited)")
+}
+
+// lokiEntry is a single log line
+type lokiEntry struct {
+	ts   time.Time
+	line string
+}
+
+// lokiStream is a set of entries sharing the same label set
+type lokiStream struct {
+	labels  map[string]string
+	entries []lokiEntry
+}
+
+// lokiEventLabels derives the configured labels for one event
+func lokiEventLabels(event []byte, derived []string, static map[string]string) map[string]string {
+	labels := make(map[string]string, len(static)+len(derived)+1)
+	for k, v := range static {
+		labels[k] = v
+	}
+
+	var header generators.SyslogHeader
+	var hasHeader bool
+	var jsonEvent map[string]interface{}
+	if generators.IsJSONEventType(lokiType) {
+		jsonEvent, _ = decodeEvent(event)
+	} else {
+		header, hasHeader = generators.ParseSyslogHeader(string(event))
+	}
+
+	for _, name := range derived {
+		var v string
+		switch name {
+		case "type":
+			v = lokiType
+		case "hostname":
+			v = header.Hostname
+		case "process":
+			v = header.AppName
+			if jsonEvent != nil {
+				v = eventField(jsonEvent, "process")
+			}
+		case "severity":
+			if hasHeader {
+				v = severityNames[header.Severity()]
+			}
+		default:
+			v = eventField(jsonEvent, name)
+		}
+		if v != "" {
+			labels[name] = v
+		}
+	}
+
+	if lokiCardinality > 0 {
+		labels["stream"] = "s" + strconv.Itoa(rand.Intn(lokiCardinality))
+	}
+	return labels
+}
+
+// lokiLabelString renders labels in Loki's {k="v", ...} selector form
+func lokiLabelString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(labels[k]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// lokiProtobuf encodes streams as a snappy-compressed PushRequest:
+//
+//	message PushRequest  { repeated StreamAdapter streams = 1; }
+//	message StreamAdapter { string labels = 1; repeated EntryAdapter entries = 2; }
+//	message EntryAdapter { google.protobuf.Timestamp timestamp = 1; string line = 2; }
+func lokiProtobuf(streams []*lokiStream) []byte {
+	var req []byte
+	for _, s := range streams {
+		var stream []byte
+		stream = protowire.AppendTag(stream, 1, protowire.BytesType)
+		stream = protowire.AppendString(stream, lokiLabelString(s.labels))
+		for _, e := range s.entries {
+			var ts []byte
+			ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(e.ts.Unix()))
+			ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(e.ts.Nanosecond()))
+
+			var entry []byte
+			entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+			entry = protowire.AppendBytes(entry, ts)
+			entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+			entry = protowire.AppendString(entry, e.line)
+
+			stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+			stream = protowire.AppendBytes(stream, entry)
+		}
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, stream)
+	}
+	return snappy.Encode(nil, req)
+}
+
+// lokiJSON encodes streams as a JSON push body
+func lokiJSON(streams []*lokiStream) ([]byte, error) {
+	type jsonStream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	body := struct {
+		Streams []jsonStream `json:"streams"`
+	}{}
+	for _, s := range streams {
+		js := jsonStream{Stream: s.labels}
+		for _, e := range s.entries {
+			js.Values = append(js.Values, [2]string{strconv.FormatInt(e.ts.UnixNano(), 10), e.line})
+		}
+		body.Streams = append(body.Streams, js)
+	}
+	return sonic.Marshal(body)
+}
+
+func runLoki(cmd *cobra.Command, args []string) error {
+	if lokiEncoding != "protobuf" && lokiEncoding != "json" {
+		return fmt.Errorf("invalid encoding: %s (must be protobuf or json)", lokiEncoding)
+	}
+	if !generators.ValidEventType(lokiType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", lokiType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(lokiLEEFVersion, lokiLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if lokiBatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	static, err := parseKeyValues(lokiStaticLabels)
+	if err != nil {
+		return fmt.Errorf("invalid --static-labels: %w", err)
+	}
+	var derived []string
+	for _, l := range strings.Split(lokiLabels, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			derived = append(derived, l)
+		}
+	}
+
+	tlsConfig, err := buildClientTLSConfig(lokiTLSCA, "", "", "", lokiTLSInsecure)
+	if err != nil {
+		return err
+	}
+	client := newHTTPClient(10*time.Second, tlsConfig, 1)
+	stats := newHTTPStats()
+	endpoint := strings.TrimRight(lokiURL, "/") + "/loki/api/v1/push"
+
+	fmt.Printf("Pushing fake %s lines to Loki at %s (%s, %d lines/s, batch size %d)\n",
+		lokiType, endpoint, lokiEncoding, lokiRate, lokiBatchSize)
+	fmt.Printf("Labels: %s", strings.Join(derived, ","))
+	if lokiCardinality > 0 {
+		fmt.Printf(" + stream (%d values)", lokiCardinality)
+	}
+	fmt.Println()
+	if lokiCount > 0 {
+		fmt.Printf("Will send %d lines total\n", lokiCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(lokiRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	generated := 0
+	pending := 0
+	startTime := time.Now()
+	opts := generators.Options{RFC: lokiRFC, LEEF: leef}
+	streams := make(map[string]*lokiStream)
+	seenStreams := make(map[string]bool)
+
+	flush := func() {
+		if pending == 0 {
+			return
+		}
+		batch := make([]*lokiStream, 0, len(streams))
+		for _, s := range streams {
+			batch = append(batch, s)
+		}
+
+		var body []byte
+		contentType := "application/x-protobuf"
+		if lokiEncoding == "json" {
+			contentType = "application/json"
+			if body, err = lokiJSON(batch); err != nil {
+				fmt.Fprintf(os.Stderr, "Error encoding push: %v\n", err)
+				return
+			}
+		} else {
+			body = lokiProtobuf(batch)
+		}
+
+		status, respBody, err := doHTTPWithRetry(client, func() (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", contentType)
+			if lokiTenant != "" {
+				req.Header.Set("X-Scope-OrgID", lokiTenant)
+			}
+			if lokiUser != "" {
+				req.SetBasicAuth(lokiUser, lokiPassword)
+			}
+			return req, nil
+		}, lokiMaxRetries, stats)
+
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error pushing: %v\n", err)
+			stats.recordFailed(pending)
+		case status < 200 || status >= 300:
+			fmt.Fprintf(os.Stderr, "Push failed with status %d: %s\n", status, strings.TrimSpace(string(respBody)))
+			stats.recordFailed(pending)
+		default:
+			before := sent
+			sent += pending
+			if sent/1000 > before/1000 {
+				fmt.Printf("Sent %d lines...\n", sent)
+			}
+		}
+
+		streams = make(map[string]*lokiStream)
+		pending = 0
+	}
+	finish := func(format string) {
+		flush()
+		fmt.Printf(format, sent, time.Since(startTime))
+		fmt.Printf("Distinct streams: %d\n", len(seenStreams))
+		stats.Print()
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d lines in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(lokiType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+
+			labels := lokiEventLabels(event, derived, static)
+			key := lokiLabelString(labels)
+			s, ok := streams[key]
+			if !ok {
+				s = &lokiStream{labels: labels}
+				streams[key] = s
+			}
+			s.entries = append(s.entries, lokiEntry{ts: time.Now(), line: string(event)})
+			seenStreams[key] = true
+			pending++
+			generated++
+
+			if pending >= lokiBatchSize {
+				flush()
+			}
+
+			if lokiCount > 0 && generated >= lokiCount {
+				finish("Completed. Sent %d lines in %v\n")
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+)
+
+var lumberjackHost string
+var lumberjackPort int
+var lumberjackType string
+var lumberjackRFC string
+var lumberjackLEEFVersion string
+var lumberjackLEEFDelimiter string
+var lumberjackBatchSize int
+var lumberjackCompression int
+var lumberjackAckTimeout time.Duration
+var lumberjackTLS bool
+var lumberjackTLSCA string
+var lumberjackTLSCert string
+var lumberjackTLSKey string
+var lumberjackTLSInsecure bool
+var lumberjackMaxRetries int
+var lumberjackRate int
+var lumberjackCount int
+
+var lumberjackCmd = &cobra.Command{
+	Use:     "lumberjack",
+	Aliases: []string{"beats"},
+	Short:   "Send fake events with the Beats/Lumberjack v2 protocol",
+	Long: `Send fake events to a Logstash beats input (or any Lumberjack v2 receiver)
+over TCP or TLS, the way Filebeat does.
+
+Events are sent in windows of --batch-size JSON frames, zlib compressed at
+--compression (0 disables compression). Each window is resent after a
+reconnect until the receiver acknowledges its last sequence number; partial
+acks and keepalives from a busy receiver extend --ack-timeout.
+
+Every event gets @timestamp and @metadata.beat fields. JSON events keep their
+fields; other event types are sent as {"message": "<line>"}.
+
+Example:
+  fakedata lumberjack --host localhost --port 5044 --rate 1000
+  fakedata lumberjack --type firewall --batch-size 2048 --compression 6
+  fakedata lumberjack --tls --tls-ca ca.pem --tls-cert client.pem --tls-key client-key.pem
+`,
+	RunE: runLumberjack,
+}
+
+func init() {
+	lumberjackCmd.Flags().StringVar(&lumberjackHost, "host", "127.0.0.1", "Target host")
+	lumberjackCmd.Flags().IntVar(&lumberjackPort, "port", 5044, "Target port")
+	lumberjackCmd.Flags().StringVar(&lumberjackType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	lumberjackCmd.Flags().StringVar(&lumberjackRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	lumberjackCmd.Flags().StringVar(&lumberjackLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	lumberjackCmd.Flags().StringVar(&lumberjackLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	lumberjackCmd.Flags().IntVar(&lumberjackBatchSize, "batch-size", 512, "Events per window")
+	lumberjackCmd.Flags().IntVar(&lumberjackCompression, "compression", 3, "zlib compression level 0-9 (0 = off)")
+	lumberjackCmd.Flags().DurationVar(&lumberjackAckTimeout, "ack-timeout", 30*time.Second, "Time to wait for an acknowledgement")
+	lumberjackCmd.Flags().BoolVar(&lumberjackTLS, "tls", false, "Connect with TLS")
+	lumberjackCmd.Flags().StringVar(&lumberjackTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	lumberjackCmd.Flags().StringVar(&lumberjackTLSCert, "tls-cert", "", "Client certificate file")
+	lumberjackCmd.Flags().StringVar(&lumberjackTLSKey, "tls-key", "", "Client private key file")
+	lumberjackCmd.Flags().BoolVar(&lumberjackTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	lumberjackCmd.Flags().IntVar(&lumberjackMaxRetries, "max-retries", 3, "Reconnect attempts per window")
+	lumberjackCmd.Flags().IntVar(&lumberjackRate, "rate", 10, "Events per second")
+	lumberjackCmd.Flags().IntVar(&lumberjackCount, "count", 0, "Total events to send (0 = unlimited)")
+}
+
+// lumberjackDocument builds the JSON document for one generated event
+func lumberjackDocument(opts generators.Options) ([]byte, error) {
+	event, err := generators.GenerateEvent(lumberjackType, opts)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]interface{}{"message": string(event)}
+	if generators.IsJSONEventType(lumberjackType) {
+		if doc, err = decodeEvent(event); err != nil {
+			return nil, err
+		}
+	}
+	ts, ok := eventTime(doc)
+	if !ok {
+		ts = time.Now()
+	}
+	doc["@timestamp"] = ts.UTC().Format(time.RFC3339Nano)
+	doc["@metadata"] = map[string]string{"beat": "fakedata", "type": "_doc"}
+	return sonic.Marshal(doc)
+}
+
+func runLumberjack(cmd *cobra.Command, args []string) error {
+	if !generators.ValidEventType(lumberjackType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", lumberjackType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(lumberjackLEEFVersion, lumberjackLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if lumberjackBatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	if lumberjackCompression < 0 || lumberjackCompression > 9 {
+		return fmt.Errorf("--compression must be between 0 and 9")
+	}
+
+	var tlsConfig *tls.Config
+	if lumberjackTLS {
+		var err error
+		tlsConfig, err = buildClientTLSConfig(lumberjackTLSCA, lumberjackTLSCert, lumberjackTLSKey, lumberjackHost, lumberjackTLSInsecure)
+		if err != nil {
+			return err
+		}
+	}
+
+	addr := fmt.Sprintf("%s:%d", lumberjackHost, lumberjackPort)
+	conn, err := dialLumberjack(addr, tlsConfig, lumberjackAckTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	fmt.Printf("Sending fake %s events to Lumberjack %s at %d events/s (window %d, compression %d)\n",
+		lumberjackType, addr, lumberjackRate, lumberjackBatchSize, lumberjackCompression)
+	if lumberjackCount > 0 {
+		fmt.Printf("Will send %d events total\n", lumberjackCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(lumberjackRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	generated := 0
+	dropped := 0
+	windows := 0
+	partialAcks := 0
+	reconnects := 0
+	var ackTime time.Duration
+	startTime := time.Now()
+	opts := generators.Options{RFC: lumberjackRFC, LEEF: leef}
+	var batch [][]byte
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		defer func() { batch = batch[:0] }()
+
+		window, err := encodeLumberjackWindow(batch, lumberjackCompression)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding window: %v\n", err)
+			dropped += len(batch)
+			return
+		}
+
+		for attempt := 0; ; attempt++ {
+			if conn == nil {
+				conn, err = dialLumberjack(addr, tlsConfig, lumberjackAckTimeout)
+				if err == nil {
+					reconnects++
+				}
+			}
+			if conn != nil {
+				start := time.Now()
+				var acks int
+				if acks, err = conn.send(window, len(batch), lumberjackAckTimeout); err == nil {
+					ackTime += time.Since(start)
+					partialAcks += acks - 1
+					break
+				}
+				conn.Close()
+				conn = nil
+			}
+			if attempt >= lumberjackMaxRetries {
+				fmt.Fprintf(os.Stderr, "Error sending window, dropping %d events: %v\n", len(batch), err)
+				dropped += len(batch)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Error sending window, reconnecting: %v\n", err)
+			time.Sleep(httpRetryDelay(nil, attempt))
+		}
+
+		windows++
+		before := sent
+		sent += len(batch)
+		if sent/1000 > before/1000 {
+			fmt.Printf("Sent %d events...\n", sent)
+		}
+	}
+	finish := func(format string) {
+		flush()
+		fmt.Printf(format, sent, time.Since(startTime))
+		if windows > 0 {
+			fmt.Printf("Acknowledged windows: %d (avg ack latency %v, partial acks %d)\n",
+				windows, ackTime/time.Duration(windows), partialAcks)
+		}
+		if reconnects > 0 || dropped > 0 {
+			fmt.Printf("Reconnects: %d, dropped: %d\n", reconnects, dropped)
+		}
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d events in %v\n")
+			return nil
+		case <-ticker.C:
+			doc, err := lumberjackDocument(opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+			batch = append(batch, doc)
+			generated++
+
+			if len(batch) >= lumberjackBatchSize {
+				flush()
+			}
+
+			if lumberjackCount > 0 && generated >= lumberjackCount {
+				finish("Completed. Sent %d events in %v\n")
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Lumberjack v2 frame types
+const (
+	lumberjackVersion    = '2'
+	lumberjackWindow     = 'W'
+	lumberjackJSON       = 'J'
+	lumberjackCompressed = 'C'
+	lumberjackAck        = 'A'
+)
+
+// lumberjackConn is a connection to a beats/lumberjack v2 input
+type lumberjackConn struct {
+	conn net.Conn
+}
+
+// dialLumberjack connects to addr over TCP or TLS
+func dialLumberjack(addr string, tlsConfig *tls.Config, timeout time.Duration) (*lumberjackConn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	var err error
+	if tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	return &lumberjackConn{conn: conn}, nil
+}
+
+// encodeLumberjackWindow frames docs as a window of JSON frames, zlib
+// compressed when level > 0. Sequence numbers start at 1 in every window.
+func encodeLumberjackWindow(docs [][]byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write([]byte{lumberjackVersion, lumberjackWindow})
+	binary.Write(&buf, binary.BigEndian, uint32(len(docs)))
+
+	var frames bytes.Buffer
+	for i, doc := range docs {
+		frames.Write([]byte{lumberjackVersion, lumberjackJSON})
+		binary.Write(&frames, binary.BigEndian, uint32(i+1))
+		binary.Write(&frames, binary.BigEndian, uint32(len(doc)))
+		frames.Write(doc)
+	}
+
+	if level <= 0 {
+		buf.Write(frames.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	var compressed bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&compressed, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(frames.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	buf.Write([]byte{lumberjackVersion, lumberjackCompressed})
+	binary.Write(&buf, binary.BigEndian, uint32(compressed.Len()))
+	buf.Write(compressed.Bytes())
+	return buf.Bytes(), nil
+}
+
+// send writes a framed window and waits until its last sequence number is
+// acknowledged. Partial acks and keepalives (seq 0) extend the deadline.
+// It returns the number of acks read.
+func (c *lumberjackConn) send(window []byte, count int, ackTimeout time.Duration) (int, error) {
+	c.conn.SetWriteDeadline(time.Now().Add(ackTimeout))
+	if _, err := c.conn.Write(window); err != nil {
+		return 0, err
+	}
+
+	acks := 0
+	frame := make([]byte, 6)
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(ackTimeout))
+		if _, err := io.ReadFull(c.conn, frame); err != nil {
+			return acks, fmt.Errorf("failed to read ack: %w", err)
+		}
+		if frame[0] != lumberjackVersion || frame[1] != lumberjackAck {
+			return acks, fmt.Errorf("unexpected frame %q", frame[:2])
+		}
+		acks++
+		seq := int(binary.BigEndian.Uint32(frame[2:]))
+		if seq > count {
+			return acks, fmt.Errorf("ack for sequence %d beyond window of %d", seq, count)
+		}
+		if seq == count {
+			c.conn.SetReadDeadline(time.Time{})
+			return acks, nil
+		}
+	}
+}
+
+func (c *lumberjackConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,228 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/bytefreezer/fakedata/generators"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/spf13/cobra"
+)
+
+var mqttBroker string
+var mqttTopic string
+var mqttType string
+var mqttRFC string
+var mqttLEEFVersion string
+var mqttLEEFDelimiter string
+var mqttQoS int
+var mqttRetain bool
+var mqttCleanSession bool
+var mqttClientID string
+var mqttUser string
+var mqttPassword string
+var mqttTLSCA string
+var mqttTLSCert string
+var mqttTLSKey string
+var mqttTLSInsecure bool
+var mqttTimeout time.Duration
+var mqttRate int
+var mqttCount int
+
+var mqttCmd = &cobra.Command{
+	Use:   "mqtt",
+	Short: "Publish fake events to an MQTT broker",
+	Long: `Publish fake events to an MQTT 3.1.1 broker (Mosquitto, EMQX, HiveMQ, ...).
+
+The topic is a template; {name} placeholders are filled from each event:
+  {type}                          Event type
+  {hostname} {process} {severity} Syslog header fields (syslog-style types)
+  {facility}
+  {source_ip} {username} ...      Any top-level field of JSON events
+
+Broker URLs use tcp://, ssl:// or ws:// schemes. TLS is used for ssl:// and
+wss:// brokers; --tls-ca, --tls-cert and --tls-key configure it.
+
+With QoS 1 or 2 every publish is tracked until the broker completes the
+handshake, and the number of completed and failed publishes is reported.
+
+Example:
+  fakedata mqtt --broker tcp://localhost:1883 --topic "fakedata/{type}" --rate 100
+  fakedata mqtt --topic "sensors/{hostname}/{process}" --type syslog --qos 1
+  fakedata mqtt --broker ssl://broker:8883 --tls-ca ca.pem --user dev --password s3cret --qos 2 --retain
+`,
+	RunE: runMQTT,
+}
+
+func init() {
+	mqttCmd.Flags().StringVar(&mqttBroker, "broker", "tcp://localhost:1883", "Broker URL(s), comma-separated")
+	mqttCmd.Flags().StringVar(&mqttTopic, "topic", "fakedata/{type}", "Topic template")
+	mqttCmd.Flags().StringVar(&mqttType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	mqttCmd.Flags().StringVar(&mqttRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	mqttCmd.Flags().StringVar(&mqttLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	mqttCmd.Flags().StringVar(&mqttLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	mqttCmd.Flags().IntVar(&mqttQoS, "qos", 0, "QoS level: 0, 1 or 2")
+	mqttCmd.Flags().BoolVar(&mqttRetain, "retain", false, "Set the retained flag")
+	mqttCmd.Flags().BoolVar(&mqttCleanSession, "clean-session", true, "Start a clean session")
+	mqttCmd.Flags().StringVar(&mqttClientID, "client-id", "", "Client ID (default fakedata-<random>)")
+	mqttCmd.Flags().StringVar(&mqttUser, "user", "", "Username for authentication")
+	mqttCmd.Flags().StringVar(&mqttPassword, "password", "", "Password for authentication")
+	mqttCmd.Flags().StringVar(&mqttTLSCA, "tls-ca", "", "CA certificate file for verifying the broker")
+	mqttCmd.Flags().StringVar(&mqttTLSCert, "tls-cert", "", "Client certificate file (mTLS)")
+	mqttCmd.Flags().StringVar(&mqttTLSKey, "tls-key", "", "Client private key file (mTLS)")
+	mqttCmd.Flags().BoolVar(&mqttTLSInsecure, "tls-insecure", false, "Skip broker certificate verification")
+	mqttCmd.Flags().DurationVar(&mqttTimeout, "timeout", 10*time.Second, "Connect and publish completion timeout")
+	mqttCmd.Flags().IntVar(&mqttRate, "rate", 10, "Messages per second")
+	mqttCmd.Flags().IntVar(&mqttCount, "count", 0, "Total messages to send (0 = unlimited)")
+}
+
+// mqttTopicFor expands the topic template for one event. Values are stripped
+// of characters that would change the topic structure.
+func mqttTopicFor(tmpl, eventType string, event []byte) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	values := eventTemplateValues(eventType, event)
+	for k, v := range values {
+		values[k] = strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(v)
+	}
+	return expandTemplate(tmpl, values)
+}
+
+func runMQTT(cmd *cobra.Command, args []string) error {
+	if mqttQoS < 0 || mqttQoS > 2 {
+		return fmt.Errorf("--qos must be 0, 1 or 2")
+	}
+	if !generators.ValidEventType(mqttType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", mqttType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(mqttLEEFVersion, mqttLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+
+	clientID := mqttClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("fakedata-%06d", rand.Intn(1000000))
+	}
+
+	clientOpts := paho.NewClientOptions().
+		SetClientID(clientID).
+		SetCleanSession(mqttCleanSession).
+		SetConnectTimeout(mqttTimeout).
+		SetAutoReconnect(true).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			fmt.Fprintf(os.Stderr, "Connection lost: %v\n", err)
+		})
+	for _, b := range strings.Split(mqttBroker, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			clientOpts.AddBroker(b)
+		}
+	}
+	if mqttUser != "" {
+		clientOpts.SetUsername(mqttUser)
+		clientOpts.SetPassword(mqttPassword)
+	}
+	if mqttTLSCA != "" || mqttTLSCert != "" || mqttTLSInsecure {
+		tlsConfig, err := buildClientTLSConfig(mqttTLSCA, mqttTLSCert, mqttTLSKey, "", mqttTLSInsecure)
+		if err != nil {
+			return err
+		}
+		clientOpts.SetTLSConfig(tlsConfig)
+	}
+
+	client := paho.NewClient(clientOpts)
+	token := client.Connect()
+	if !token.WaitTimeout(mqttTimeout) {
+		return fmt.Errorf("timed out connecting to %s", mqttBroker)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", mqttBroker, err)
+	}
+	defer client.Disconnect(250)
+
+	fmt.Printf("Connected to MQTT broker %s as %s\n", mqttBroker, clientID)
+	fmt.Printf("Publishing fake %s events to %s (QoS %d, retain %v) at %d msg/s\n",
+		mqttType, mqttTopic, mqttQoS, mqttRetain, mqttRate)
+	if mqttCount > 0 {
+		fmt.Printf("Will send %d messages total\n", mqttCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(mqttRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	var completed, failed atomic.Int64
+	var inflight sync.WaitGroup
+	startTime := time.Now()
+	opts := generators.Options{RFC: mqttRFC, LEEF: leef}
+
+	finish := func(format string) {
+		inflight.Wait()
+		fmt.Printf(format, sent, time.Since(startTime))
+		if mqttQoS > 0 {
+			fmt.Printf("Completed QoS %d publishes: %d, failed: %d\n", mqttQoS, completed.Load(), failed.Load())
+		}
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d messages in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(mqttType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+
+			topic := mqttTopicFor(mqttTopic, mqttType, event)
+			t := client.Publish(topic, byte(mqttQoS), mqttRetain, event)
+			if mqttQoS > 0 {
+				inflight.Add(1)
+				go func(t paho.Token) {
+					defer inflight.Done()
+					if !t.WaitTimeout(mqttTimeout) {
+						failed.Add(1)
+						fmt.Fprintf(os.Stderr, "Publish to %s timed out\n", topic)
+						return
+					}
+					if err := t.Error(); err != nil {
+						failed.Add(1)
+						fmt.Fprintf(os.Stderr, "Error publishing to %s: %v\n", topic, err)
+						return
+					}
+					completed.Add(1)
+				}(t)
+			}
+
+			sent++
+			if sent%1000 == 0 {
+				fmt.Printf("Sent %d messages...\n", sent)
+			}
+
+			if mqttCount > 0 && sent >= mqttCount {
+				finish("Completed. Sent %d messages in %v\n")
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/bytefreezer/fakedata/generators"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/spf13/cobra"
+)
+
+var mqttServerHost string
+var mqttServerPort int
+var mqttServerWSPort int
+var mqttServerTopic string
+var mqttServerType string
+var mqttServerRFC string
+var mqttServerLEEFVersion string
+var mqttServerLEEFDelimiter string
+var mqttServerQoS int
+var mqttServerRetain bool
+var mqttServerUser string
+var mqttServerPassword string
+var mqttServerTLSCert string
+var mqttServerTLSKey string
+var mqttServerRate int
+var mqttServerCount int
+
+var mqttServerCmd = &cobra.Command{
+	Use:   "mqtt-server",
+	Short: "Run embedded MQTT broker and publish fake data (no external dependencies)",
+	Long: `Start an embedded MQTT broker and publish fake events to it.
+
+Like nats-server, this needs no Mosquitto or other infrastructure: point your
+MQTT consumer at the embedded broker and subscribe to the topic. The topic
+accepts the same {name} placeholders as the mqtt command.
+
+Example:
+  # Broker on port 1883, publishing to fakedata/json
+  fakedata mqtt-server --port 1883 --rate 100
+
+  # Consume with any client
+  mosquitto_sub -h localhost -p 1883 -t 'fakedata/#' -v
+
+  # Per-host topics, QoS 1, plus a websocket listener on 8080
+  fakedata mqtt-server --type syslog --topic "logs/{hostname}" --qos 1 --ws-port 8080
+
+  # Require username/password over TLS
+  fakedata mqtt-server --user app --password s3cret --tls-cert server.pem --tls-key server-key.pem
+`,
+	RunE: runMQTTServer,
+}
+
+func init() {
+	mqttServerCmd.Flags().StringVar(&mqttServerHost, "host", "0.0.0.0", "Address to listen on")
+	mqttServerCmd.Flags().IntVar(&mqttServerPort, "port", 1883, "MQTT listener port")
+	mqttServerCmd.Flags().IntVar(&mqttServerWSPort, "ws-port", 0, "Websocket listener port (0 = disabled)")
+	mqttServerCmd.Flags().StringVar(&mqttServerTopic, "topic", "fakedata/{type}", "Topic template")
+	mqttServerCmd.Flags().StringVar(&mqttServerType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	mqttServerCmd.Flags().StringVar(&mqttServerRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	mqttServerCmd.Flags().StringVar(&mqttServerLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	mqttServerCmd.Flags().StringVar(&mqttServerLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	mqttServerCmd.Flags().IntVar(&mqttServerQoS, "qos", 0, "QoS level: 0, 1 or 2")
+	mqttServerCmd.Flags().BoolVar(&mqttServerRetain, "retain", false, "Set the retained flag")
+	mqttServerCmd.Flags().StringVar(&mqttServerUser, "user", "", "Require this username for clients")
+	mqttServerCmd.Flags().StringVar(&mqttServerPassword, "password", "", "Password for --user")
+	mqttServerCmd.Flags().StringVar(&mqttServerTLSCert, "tls-cert", "", "Server certificate file (enables TLS)")
+	mqttServerCmd.Flags().StringVar(&mqttServerTLSKey, "tls-key", "", "Server private key file")
+	mqttServerCmd.Flags().IntVar(&mqttServerRate, "rate", 10, "Messages per second")
+	mqttServerCmd.Flags().IntVar(&mqttServerCount, "count", 0, "Total messages to send (0 = unlimited)")
+}
+
+func runMQTTServer(cmd *cobra.Command, args []string) error {
+	if mqttServerQoS < 0 || mqttServerQoS > 2 {
+		return fmt.Errorf("--qos must be 0, 1 or 2")
+	}
+	if !generators.ValidEventType(mqttServerType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", mqttServerType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(mqttServerLEEFVersion, mqttServerLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+
+	var tlsConfig *tls.Config
+	if mqttServerTLSCert != "" || mqttServerTLSKey != "" {
+		if mqttServerTLSCert == "" || mqttServerTLSKey == "" {
+			return fmt.Errorf("both --tls-cert and --tls-key are required for TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(mqttServerTLSCert, mqttServerTLSKey)
+		if err != nil {
+			return fmt.Errorf("failed to load server certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	// Create embedded broker with an inline client for publishing
+	ms := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	if mqttServerUser != "" {
+		err := ms.AddHook(new(auth.Hook), &auth.Options{
+			Ledger: &auth.Ledger{
+				Auth: auth.AuthRules{
+					{Username: auth.RString(mqttServerUser), Password: auth.RString(mqttServerPassword), Allow: true},
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to configure authentication: %w", err)
+		}
+	} else if err := ms.AddHook(new(auth.AllowHook), nil); err != nil {
+		return fmt.Errorf("failed to configure authentication: %w", err)
+	}
+
+	addr := fmt.Sprintf("%s:%d", mqttServerHost, mqttServerPort)
+	if err := ms.AddListener(listeners.NewTCP(listeners.Config{ID: "tcp", Address: addr, TLSConfig: tlsConfig})); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if mqttServerWSPort > 0 {
+		wsAddr := fmt.Sprintf("%s:%d", mqttServerHost, mqttServerWSPort)
+		if err := ms.AddListener(listeners.NewWebsocket(listeners.Config{ID: "ws", Address: wsAddr, TLSConfig: tlsConfig})); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", wsAddr, err)
+		}
+	}
+
+	if err := ms.Serve(); err != nil {
+		return fmt.Errorf("failed to start MQTT broker: %w", err)
+	}
+	defer ms.Close()
+
+	scheme := "tcp"
+	if tlsConfig != nil {
+		scheme = "ssl"
+	}
+	fmt.Printf("Embedded MQTT broker started on %s\n", addr)
+	fmt.Printf("Configure consumers to connect to: %s://localhost:%d\n", scheme, mqttServerPort)
+	if mqttServerWSPort > 0 {
+		fmt.Printf("Websocket listener on port %d\n", mqttServerWSPort)
+	}
+	if mqttServerUser != "" {
+		fmt.Println("Authentication required")
+	}
+	fmt.Printf("Publishing fake %s events to %s (QoS %d) at %d msg/s\n",
+		mqttServerType, mqttServerTopic, mqttServerQoS, mqttServerRate)
+	if mqttServerCount > 0 {
+		fmt.Printf("Will send %d messages total\n", mqttServerCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(mqttServerRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	startTime := time.Now()
+	opts := generators.Options{RFC: mqttServerRFC, LEEF: leef}
+
+	printDelivery := func() {
+		fmt.Printf("Clients connected: %d, messages delivered: %d\n",
+			atomic.LoadInt64(&ms.Info.ClientsConnected), atomic.LoadInt64(&ms.Info.MessagesSent))
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			fmt.Printf("\nStopping...\n")
+			printDelivery()
+			fmt.Printf("Sent %d messages in %v\n", sent, time.Since(startTime))
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(mqttServerType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+
+			topic := mqttTopicFor(mqttServerTopic, mqttServerType, event)
+			if err := ms.Publish(topic, event, mqttServerRetain, byte(mqttServerQoS)); err != nil {
+				fmt.Fprintf(os.Stderr, "Error publishing: %v\n", err)
+				continue
+			}
+
+			sent++
+			if sent%1000 == 0 {
+				fmt.Printf("Sent %d messages...\n", sent)
+			}
+
+			if mqttServerCount > 0 && sent >= mqttServerCount {
+				// Keep the broker running so consumers can drain retained
+				// and queued messages
+				fmt.Printf("Sent %d messages. Broker will continue running for consumption.\n", sent)
+				fmt.Println("Press Ctrl+C to stop broker")
+				<-sigChan
+				printDelivery()
+				fmt.Printf("Completed in %v\n", time.Since(startTime))
+				return nil
+			}
+		}
+	}
+}
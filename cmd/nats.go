@@ -19,6 +19,16 @@ var natsServers string
 var natsSubject string
 var natsRate int
 var natsCount int
+var natsToken string
+var natsUser string
+var natsPassword string
+var natsNkeySeed string
+var natsCreds string
+var natsTLS bool
+var natsTLSCA string
+var natsTLSCert string
+var natsTLSKey string
+var natsTLSInsecure bool
 
 var natsCmd = &cobra.Command{
 	Use:   "nats",
@@ -28,8 +38,21 @@ var natsCmd = &cobra.Command{
 NATS is very lightweight and can run locally for testing:
   docker run -p 4222:4222 nats:latest
 
+Authentication (use one):
+  --token             Token authentication
+  --user/--password   Username and password
+  --nkey-seed         NKey seed file
+  --creds             User JWT credentials file (decentralized auth)
+
+TLS:
+  --tls enables TLS using the system roots; --tls-ca, --tls-cert and
+  --tls-key also enable it and configure a custom CA and client certificate.
+
 Example:
   fakedata nats --servers nats://localhost:4222 --subject events.test --rate 100
+  fakedata nats --servers nats://localhost:4222 --token s3cret
+  fakedata nats --servers nats://localhost:4222 --user app --password s3cret
+  fakedata nats --servers tls://localhost:4222 --tls-ca ca.pem --tls-cert client.pem --tls-key client-key.pem
 `,
 	RunE: runNATS,
 }
@@ -39,13 +62,68 @@ func init() {
 	natsCmd.Flags().StringVar(&natsSubject, "subject", "bytefreezer.events", "Subject to publish to")
 	natsCmd.Flags().IntVar(&natsRate, "rate", 10, "Messages per second")
 	natsCmd.Flags().IntVar(&natsCount, "count", 0, "Total messages to send (0 = unlimited)")
+	natsCmd.Flags().StringVar(&natsToken, "token", "", "Authentication token")
+	natsCmd.Flags().StringVar(&natsUser, "user", "", "Username for authentication")
+	natsCmd.Flags().StringVar(&natsPassword, "password", "", "Password for authentication")
+	natsCmd.Flags().StringVar(&natsNkeySeed, "nkey-seed", "", "NKey seed file for authentication")
+	natsCmd.Flags().StringVar(&natsCreds, "creds", "", "User credentials file (JWT + seed)")
+	natsCmd.Flags().BoolVar(&natsTLS, "tls", false, "Enable TLS")
+	natsCmd.Flags().StringVar(&natsTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	natsCmd.Flags().StringVar(&natsTLSCert, "tls-cert", "", "Client certificate file (mTLS)")
+	natsCmd.Flags().StringVar(&natsTLSKey, "tls-key", "", "Client private key file (mTLS)")
+	natsCmd.Flags().BoolVar(&natsTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+}
+
+// natsAuthOptions builds the authentication and TLS connect options from flags
+func natsAuthOptions() ([]nats.Option, error) {
+	var opts []nats.Option
+
+	methods := 0
+	if natsToken != "" {
+		opts = append(opts, nats.Token(natsToken))
+		methods++
+	}
+	if natsUser != "" {
+		opts = append(opts, nats.UserInfo(natsUser, natsPassword))
+		methods++
+	}
+	if natsNkeySeed != "" {
+		opt, err := nats.NkeyOptionFromSeed(natsNkeySeed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load nkey seed: %w", err)
+		}
+		opts = append(opts, opt)
+		methods++
+	}
+	if natsCreds != "" {
+		opts = append(opts, nats.UserCredentials(natsCreds))
+		methods++
+	}
+	if methods > 1 {
+		return nil, fmt.Errorf("only one of --token, --user, --nkey-seed or --creds may be set")
+	}
+
+	if natsTLS || natsTLSCA != "" || natsTLSCert != "" || natsTLSKey != "" || natsTLSInsecure {
+		tlsConfig, err := buildClientTLSConfig(natsTLSCA, natsTLSCert, natsTLSKey, "", natsTLSInsecure)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+
+	return opts, nil
 }
 
 func runNATS(cmd *cobra.Command, args []string) error {
+	authOpts, err := natsAuthOptions()
+	if err != nil {
+		return err
+	}
+
 	// Connect to NATS
-	nc, err := nats.Connect(natsServers,
+	opts := append([]nats.Option{
 		nats.MaxReconnects(-1),
-		nats.ReconnectWait(2*time.Second),
+		nats.ReconnectWait(2 * time.Second),
 		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "NATS disconnected: %v\n", err)
@@ -54,7 +132,8 @@ func runNATS(cmd *cobra.Command, args []string) error {
 		nats.ReconnectHandler(func(nc *nats.Conn) {
 			fmt.Printf("NATS reconnected to %s\n", nc.ConnectedUrl())
 		}),
-	)
+	}, authOpts...)
+	nc, err := nats.Connect(natsServers, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to NATS at %s: %w", natsServers, err)
 	}
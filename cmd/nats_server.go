@@ -4,22 +4,50 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/bytefreezer/fakedata/generators"
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/nats-io/nkeys"
 	"github.com/spf13/cobra"
 )
 
+var natsServerHost string
 var natsServerPort int
 var natsServerSubject string
 var natsServerRate int
 var natsServerCount int
+var natsServerToken string
+var natsServerUser string
+var natsServerPassword string
+var natsServerNkeySeed string
+var natsServerTLSCert string
+var natsServerTLSKey string
+var natsServerTLSCA string
+var natsServerTLSVerify bool
+var natsServerConfig string
+var natsServerName string
+var natsServerJetStream bool
+var natsServerJSStoreDir string
+var natsServerJSMaxMem int64
+var natsServerJSMaxFile int64
+var natsServerJSStream string
+var natsServerJSStorage string
+var natsServerHTTPPort int
+var natsServerClusterName string
+var natsServerClusterPort int
+var natsServerRoutes string
+var natsServerLeafPort int
+var natsServerLeafRemotes string
 
 var natsServerCmd = &cobra.Command{
 	Use:   "nats-server",
@@ -35,26 +63,230 @@ Example:
   fakedata nats-server --port 4222 --subject events.test --rate 100
 
   # Configure proxy to consume from nats://localhost:4222 subject "events.test"
+
+  # Require token authentication
+  fakedata nats-server --port 4222 --token s3cret
+
+  # Require username/password over TLS, with client certificates (mTLS)
+  fakedata nats-server --user app --password s3cret \
+    --tls-cert server.pem --tls-key server-key.pem --tls-ca ca.pem --tls-verify
+
+  # JetStream with a file store, publishing into a stream with acks
+  fakedata nats-server --jetstream --js-store-dir /tmp/nats-js --js-stream EVENTS
+
+  # HTTP monitoring on :8222, leafnode listener on :7422
+  fakedata nats-server --http-port 8222 --leafnode-port 7422
+
+  # Two-node cluster
+  fakedata nats-server --name n1 --port 4222 --cluster-name c1 --cluster-port 6222 \
+    --routes nats://localhost:6223
+  fakedata nats-server --name n2 --port 4223 --cluster-name c1 --cluster-port 6223 \
+    --routes nats://localhost:6222
+
+  # Load a full nats-server configuration file; explicit flags override it
+  fakedata nats-server --config nats.conf
+
+The embedded publisher connects in-process using the same credentials, so
+only external consumers go through the configured auth and TLS. If the
+config file enables authentication, pass matching --token/--user/--nkey-seed
+flags so the embedded publisher can connect.
 `,
 	RunE: runNATSServer,
 }
 
 func init() {
+	natsServerCmd.Flags().StringVar(&natsServerHost, "host", "0.0.0.0", "Address to listen on")
 	natsServerCmd.Flags().IntVar(&natsServerPort, "port", 4222, "NATS server port")
 	natsServerCmd.Flags().StringVar(&natsServerSubject, "subject", "bytefreezer.events", "Subject to publish to")
 	natsServerCmd.Flags().IntVar(&natsServerRate, "rate", 10, "Messages per second")
 	natsServerCmd.Flags().IntVar(&natsServerCount, "count", 0, "Total messages to send (0 = unlimited)")
+	natsServerCmd.Flags().StringVar(&natsServerToken, "token", "", "Require token authentication")
+	natsServerCmd.Flags().StringVar(&natsServerUser, "user", "", "Require username for authentication")
+	natsServerCmd.Flags().StringVar(&natsServerPassword, "password", "", "Password for --user")
+	natsServerCmd.Flags().StringVar(&natsServerNkeySeed, "nkey-seed", "", "NKey seed file; clients must authenticate with this nkey")
+	natsServerCmd.Flags().StringVar(&natsServerTLSCert, "tls-cert", "", "Server certificate file (enables TLS)")
+	natsServerCmd.Flags().StringVar(&natsServerTLSKey, "tls-key", "", "Server private key file")
+	natsServerCmd.Flags().StringVar(&natsServerTLSCA, "tls-ca", "", "CA certificate file for verifying client certificates")
+	natsServerCmd.Flags().BoolVar(&natsServerTLSVerify, "tls-verify", false, "Require and verify client certificates (mTLS)")
+	natsServerCmd.Flags().StringVar(&natsServerConfig, "config", "", "nats-server configuration file")
+	natsServerCmd.Flags().StringVar(&natsServerName, "name", "", "Server name (required for clustered JetStream)")
+	natsServerCmd.Flags().BoolVar(&natsServerJetStream, "jetstream", false, "Enable JetStream")
+	natsServerCmd.Flags().StringVar(&natsServerJSStoreDir, "js-store-dir", "", "JetStream storage directory (default: nats/jetstream under the OS temp dir)")
+	natsServerCmd.Flags().Int64Var(&natsServerJSMaxMem, "js-max-mem", 0, "JetStream max memory storage in bytes (0 = server default)")
+	natsServerCmd.Flags().Int64Var(&natsServerJSMaxFile, "js-max-file", 0, "JetStream max file storage in bytes (0 = server default)")
+	natsServerCmd.Flags().StringVar(&natsServerJSStream, "js-stream", "", "Create this stream on the subject and publish through JetStream")
+	natsServerCmd.Flags().StringVar(&natsServerJSStorage, "js-storage", "file", "Storage type for --js-stream: file or memory")
+	natsServerCmd.Flags().IntVar(&natsServerHTTPPort, "http-port", 0, "HTTP monitoring port (0 = disabled)")
+	natsServerCmd.Flags().StringVar(&natsServerClusterName, "cluster-name", "", "Cluster name")
+	natsServerCmd.Flags().IntVar(&natsServerClusterPort, "cluster-port", 0, "Cluster listener port (0 = disabled)")
+	natsServerCmd.Flags().StringVar(&natsServerRoutes, "routes", "", "Cluster routes to solicit, comma-separated")
+	natsServerCmd.Flags().IntVar(&natsServerLeafPort, "leafnode-port", 0, "Leafnode listener port (0 = disabled)")
+	natsServerCmd.Flags().StringVar(&natsServerLeafRemotes, "leafnode-remotes", "", "Remote leafnode URLs to connect to, comma-separated")
 }
 
-func runNATSServer(cmd *cobra.Command, args []string) error {
-	// Create embedded NATS server
+// buildNATSServerOptions loads the optional config file and applies flags on
+// top of it. Flags only override the file when explicitly set.
+func buildNATSServerOptions(cmd *cobra.Command) (*server.Options, error) {
 	opts := &server.Options{
-		Host:           "0.0.0.0",
+		Host:           natsServerHost,
 		Port:           natsServerPort,
-		NoLog:          true,
-		NoSigs:         true,
 		MaxControlLine: 4096,
 	}
+	fromFile := natsServerConfig != ""
+	if fromFile {
+		fileOpts, err := server.ProcessConfigFile(natsServerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config %s: %w", natsServerConfig, err)
+		}
+		opts = fileOpts
+	}
+	opts.NoLog = true
+	opts.NoSigs = true
+
+	set := func(name string) bool {
+		return !fromFile || cmd.Flags().Changed(name)
+	}
+
+	if set("host") {
+		opts.Host = natsServerHost
+	}
+	if set("port") {
+		opts.Port = natsServerPort
+	}
+	if natsServerName != "" {
+		opts.ServerName = natsServerName
+	}
+
+	if natsServerJetStream || natsServerJSStream != "" {
+		opts.JetStream = true
+	}
+	if opts.JetStream {
+		if natsServerJSStoreDir != "" {
+			opts.StoreDir = natsServerJSStoreDir
+		}
+		if natsServerJSMaxMem > 0 {
+			opts.JetStreamMaxMemory = natsServerJSMaxMem
+		}
+		if natsServerJSMaxFile > 0 {
+			opts.JetStreamMaxStore = natsServerJSMaxFile
+		}
+	}
+
+	if natsServerHTTPPort > 0 {
+		opts.HTTPHost = opts.Host
+		opts.HTTPPort = natsServerHTTPPort
+	}
+
+	if natsServerClusterPort > 0 {
+		opts.Cluster.Host = opts.Host
+		opts.Cluster.Port = natsServerClusterPort
+	}
+	if natsServerClusterName != "" {
+		opts.Cluster.Name = natsServerClusterName
+	}
+	if natsServerRoutes != "" {
+		opts.Routes = server.RoutesFromStr(natsServerRoutes)
+	}
+
+	if natsServerLeafPort > 0 {
+		opts.LeafNode.Host = opts.Host
+		opts.LeafNode.Port = natsServerLeafPort
+	}
+	for _, remote := range strings.Split(natsServerLeafRemotes, ",") {
+		remote = strings.TrimSpace(remote)
+		if remote == "" {
+			continue
+		}
+		u, err := url.Parse(remote)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leafnode remote %s: %w", remote, err)
+		}
+		opts.LeafNode.Remotes = append(opts.LeafNode.Remotes, &server.RemoteLeafOpts{URLs: []*url.URL{u}})
+	}
+
+	return opts, nil
+}
+
+// configureNATSServerAuth applies auth and TLS flags to the server options and
+// returns the client options the embedded publisher needs to authenticate.
+func configureNATSServerAuth(opts *server.Options) ([]nats.Option, error) {
+	var clientOpts []nats.Option
+
+	methods := 0
+	if natsServerToken != "" {
+		opts.Authorization = natsServerToken
+		clientOpts = append(clientOpts, nats.Token(natsServerToken))
+		methods++
+	}
+	if natsServerUser != "" {
+		opts.Username = natsServerUser
+		opts.Password = natsServerPassword
+		clientOpts = append(clientOpts, nats.UserInfo(natsServerUser, natsServerPassword))
+		methods++
+	}
+	if natsServerNkeySeed != "" {
+		seed, err := os.ReadFile(natsServerNkeySeed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read nkey seed: %w", err)
+		}
+		kp, err := nkeys.ParseDecoratedNKey(seed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse nkey seed: %w", err)
+		}
+		pub, err := kp.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive nkey public key: %w", err)
+		}
+		opts.Nkeys = []*server.NkeyUser{{Nkey: pub}}
+		opt, err := nats.NkeyOptionFromSeed(natsServerNkeySeed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load nkey seed: %w", err)
+		}
+		clientOpts = append(clientOpts, opt)
+		methods++
+	}
+	if methods > 1 {
+		return nil, fmt.Errorf("only one of --token, --user or --nkey-seed may be set")
+	}
+
+	if natsServerTLSCert != "" || natsServerTLSKey != "" {
+		if natsServerTLSCert == "" || natsServerTLSKey == "" {
+			return nil, fmt.Errorf("both --tls-cert and --tls-key are required for TLS")
+		}
+		tlsConfig, err := server.GenTLSConfig(&server.TLSConfigOpts{
+			CertFile: natsServerTLSCert,
+			KeyFile:  natsServerTLSKey,
+			CaFile:   natsServerTLSCA,
+			Verify:   natsServerTLSVerify,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		opts.TLS = true
+		opts.TLSConfig = tlsConfig
+		opts.TLSVerify = natsServerTLSVerify
+	} else if natsServerTLSCA != "" || natsServerTLSVerify {
+		return nil, fmt.Errorf("--tls-ca and --tls-verify require --tls-cert and --tls-key")
+	}
+
+	return clientOpts, nil
+}
+
+func runNATSServer(cmd *cobra.Command, args []string) error {
+	if natsServerJSStorage != "file" && natsServerJSStorage != "memory" {
+		return fmt.Errorf("invalid JetStream storage: %s (must be file or memory)", natsServerJSStorage)
+	}
+
+	// Create embedded NATS server
+	opts, err := buildNATSServerOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	clientOpts, err := configureNATSServerAuth(opts)
+	if err != nil {
+		return err
+	}
 
 	ns, err := server.NewServer(opts)
 	if err != nil {
@@ -69,18 +301,67 @@ func runNATSServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("NATS server failed to start within timeout")
 	}
 
-	fmt.Printf("Embedded NATS server started on port %d\n", natsServerPort)
-	fmt.Printf("Configure proxy to connect to: nats://localhost:%d\n", natsServerPort)
+	scheme := "nats"
+	if opts.TLS {
+		scheme = "tls"
+	}
+	fmt.Printf("Embedded NATS server started on %s:%d\n", opts.Host, opts.Port)
+	fmt.Printf("Configure proxy to connect to: %s://localhost:%d\n", scheme, opts.Port)
+	if opts.JetStream {
+		fmt.Printf("JetStream enabled (store: %s)\n", ns.StoreDir())
+	}
+	if opts.HTTPPort > 0 {
+		fmt.Printf("Monitoring available at http://localhost:%d\n", opts.HTTPPort)
+	}
+	if opts.Cluster.Port > 0 {
+		fmt.Printf("Cluster %q listening on port %d\n", opts.Cluster.Name, opts.Cluster.Port)
+	}
+	if opts.LeafNode.Port > 0 {
+		fmt.Printf("Leafnode listener on port %d\n", opts.LeafNode.Port)
+	}
+	if len(opts.LeafNode.Remotes) > 0 {
+		fmt.Printf("Connecting to %d leafnode remote(s)\n", len(opts.LeafNode.Remotes))
+	}
+	if opts.Authorization != "" || opts.Username != "" || len(opts.Nkeys) > 0 {
+		fmt.Println("Authentication required")
+	}
+	if opts.TLSVerify {
+		fmt.Println("Client certificates required (mTLS)")
+	}
 	fmt.Printf("Publishing to subject: %s\n", natsServerSubject)
 
-	// Connect to embedded server
-	nc, err := nats.Connect(fmt.Sprintf("nats://localhost:%d", natsServerPort))
+	// Connect to embedded server in-process, bypassing the network listener
+	nc, err := nats.Connect("", append(clientOpts, nats.InProcessServer(ns))...)
 	if err != nil {
 		ns.Shutdown()
 		return fmt.Errorf("failed to connect to embedded NATS: %w", err)
 	}
 	defer nc.Close()
 
+	// Optionally route publishes through a JetStream stream so they are acked
+	var js jetstream.JetStream
+	if natsServerJSStream != "" {
+		js, err = jetstream.New(nc)
+		if err != nil {
+			ns.Shutdown()
+			return fmt.Errorf("failed to create JetStream context: %w", err)
+		}
+		storage := jetstream.FileStorage
+		if natsServerJSStorage == "memory" {
+			storage = jetstream.MemoryStorage
+		}
+		_, err = js.CreateOrUpdateStream(context.Background(), jetstream.StreamConfig{
+			Name:     natsServerJSStream,
+			Subjects: []string{natsServerSubject},
+			Storage:  storage,
+		})
+		if err != nil {
+			ns.Shutdown()
+			return fmt.Errorf("failed to create stream %s: %w", natsServerJSStream, err)
+		}
+		fmt.Printf("Publishing through JetStream stream '%s' (%s storage)\n", natsServerJSStream, natsServerJSStorage)
+	}
+
 	fmt.Printf("Sending fake JSON at %d msg/s\n", natsServerRate)
 	if natsServerCount > 0 {
 		fmt.Printf("Will send %d messages total\n", natsServerCount)
@@ -113,7 +394,12 @@ func runNATSServer(cmd *cobra.Command, args []string) error {
 				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
 				continue
 			}
-			if err := nc.Publish(natsServerSubject, event); err != nil {
+			if js != nil {
+				_, err = js.Publish(context.Background(), natsServerSubject, event)
+			} else {
+				err = nc.Publish(natsServerSubject, event)
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error publishing: %v\n", err)
 				continue
 			}
@@ -0,0 +1,411 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var otlpEndpoint string
+var otlpProtocol string
+var otlpType string
+var otlpRFC string
+var otlpLEEFVersion string
+var otlpLEEFDelimiter string
+var otlpServiceName string
+var otlpBatchSize int
+var otlpGzip bool
+var otlpHeaders string
+var otlpTLS bool
+var otlpTLSCA string
+var otlpTLSCert string
+var otlpTLSKey string
+var otlpTLSInsecure bool
+var otlpTimeout time.Duration
+var otlpMaxRetries int
+var otlpRate int
+var otlpCount int
+
+var otlpCmd = &cobra.Command{
+	Use:   "otlp",
+	Short: "Export fake events as OTLP logs (gRPC or HTTP)",
+	Long: `Export fake events as OpenTelemetry log records to an OTLP receiver such as
+the OpenTelemetry Collector.
+
+Protocols:
+  grpc           OTLP/gRPC (default endpoint localhost:4317)
+  http/protobuf  OTLP/HTTP with protobuf bodies (default http://localhost:4318)
+  http/json      OTLP/HTTP with JSON bodies
+
+Mapping:
+  Body                 The generated line or JSON document
+  SeverityNumber/Text  From the syslog priority (emerg=FATAL4 ... debug=DEBUG);
+                       JSON events are INFO
+  Resource             host.name and process.executable.name from the syslog
+                       header (or the JSON "process" field); service.name is
+                       the process, falling back to --service-name
+  Attributes           Every JSON event field, or event.type and
+                       syslog.facility/syslog.priority for syslog-style events
+
+Exports are retried with backoff on retryable gRPC codes (UNAVAILABLE,
+RESOURCE_EXHAUSTED, ...) and HTTP statuses (429, 502, 503, 504). Records
+rejected through partial success responses are reported.
+
+Example:
+  fakedata otlp --endpoint localhost:4317 --type syslog --rate 1000
+  fakedata otlp --protocol http/protobuf --endpoint http://localhost:4318 --gzip --batch-size 500
+  fakedata otlp --protocol http/json --type firewall --headers "Authorization=Bearer abc"
+  fakedata otlp --endpoint collector:4317 --tls --tls-ca ca.pem
+`,
+	RunE: runOTLP,
+}
+
+func init() {
+	otlpCmd.Flags().StringVar(&otlpEndpoint, "endpoint", "", "Receiver endpoint (default localhost:4317 for grpc, http://localhost:4318 for http)")
+	otlpCmd.Flags().StringVar(&otlpProtocol, "protocol", "grpc", "Protocol: grpc, http/protobuf or http/json")
+	otlpCmd.Flags().StringVar(&otlpType, "type", "syslog", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	otlpCmd.Flags().StringVar(&otlpRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	otlpCmd.Flags().StringVar(&otlpLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	otlpCmd.Flags().StringVar(&otlpLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	otlpCmd.Flags().StringVar(&otlpServiceName, "service-name", "fakedata", "service.name for events without a process")
+	otlpCmd.Flags().IntVar(&otlpBatchSize, "batch-size", 100, "Log records per export request")
+	otlpCmd.Flags().BoolVar(&otlpGzip, "gzip", false, "Compress requests with gzip")
+	otlpCmd.Flags().StringVar(&otlpHeaders, "headers", "", "Extra headers/metadata, e.g. Authorization=Bearer abc")
+	otlpCmd.Flags().BoolVar(&otlpTLS, "tls", false, "Use TLS for gRPC (HTTP uses the URL scheme)")
+	otlpCmd.Flags().StringVar(&otlpTLSCA, "tls-ca", "", "CA certificate file for verifying the receiver")
+	otlpCmd.Flags().StringVar(&otlpTLSCert, "tls-cert", "", "Client certificate file (mTLS)")
+	otlpCmd.Flags().StringVar(&otlpTLSKey, "tls-key", "", "Client private key file (mTLS)")
+	otlpCmd.Flags().BoolVar(&otlpTLSInsecure, "tls-insecure", false, "Skip receiver certificate verification")
+	otlpCmd.Flags().DurationVar(&otlpTimeout, "timeout", 10*time.Second, "Export request timeout")
+	otlpCmd.Flags().IntVar(&otlpMaxRetries, "max-retries", 3, "Retries for failed exports")
+	otlpCmd.Flags().IntVar(&otlpRate, "rate", 10, "Log records per second")
+	otlpCmd.Flags().IntVar(&otlpCount, "count", 0, "Total log records to send (0 = unlimited)")
+}
+
+// otlpExporter sends export requests over one of the OTLP transports
+type otlpExporter interface {
+	Export(req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error)
+	Print()
+	Close() error
+}
+
+// otlpGRPCExporter exports over OTLP/gRPC
+type otlpGRPCExporter struct {
+	conn   *grpc.ClientConn
+	client collogspb.LogsServiceClient
+	md     metadata.MD
+	codes  map[codes.Code]int
+}
+
+func newOTLPGRPCExporter(endpoint string, headers map[string]string) (*otlpGRPCExporter, error) {
+	creds := insecure.NewCredentials()
+	if otlpTLS {
+		tlsConfig, err := buildClientTLSConfig(otlpTLSCA, otlpTLSCert, otlpTLSKey, "", otlpTLSInsecure)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for %s: %w", endpoint, err)
+	}
+	return &otlpGRPCExporter{
+		conn:   conn,
+		client: collogspb.NewLogsServiceClient(conn),
+		md:     metadata.New(headers),
+		codes:  make(map[codes.Code]int),
+	}, nil
+}
+
+// otlpGRPCRetryable reports whether an export failing with code may be
+// retried, per the OTLP specification
+func otlpGRPCRetryable(code codes.Code) bool {
+	switch code {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted,
+		codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// otlpHTTPRetryable reports whether an export failing with an HTTP status may
+// be retried, per the OTLP specification
+func otlpHTTPRetryable(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (e *otlpGRPCExporter) Export(req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	var callOpts []grpc.CallOption
+	if otlpGzip {
+		callOpts = append(callOpts, grpc.UseCompressor(gzip.Name))
+	}
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(context.Background(), e.md), otlpTimeout)
+		resp, err := e.client.Export(ctx, req, callOpts...)
+		cancel()
+
+		code := status.Code(err)
+		e.codes[code]++
+		if err == nil {
+			return resp, nil
+		}
+		if !otlpGRPCRetryable(code) || attempt >= otlpMaxRetries {
+			return nil, err
+		}
+		time.Sleep(httpRetryDelay(nil, attempt))
+	}
+}
+
+func (e *otlpGRPCExporter) Print() {
+	names := make([]string, 0, len(e.codes))
+	counts := make(map[string]int, len(e.codes))
+	for code, n := range e.codes {
+		names = append(names, code.String())
+		counts[code.String()] = n
+	}
+	sort.Strings(names)
+	fmt.Println("gRPC status codes:")
+	for _, name := range names {
+		fmt.Printf("  %s: %d\n", name, counts[name])
+	}
+}
+
+func (e *otlpGRPCExporter) Close() error {
+	return e.conn.Close()
+}
+
+// otlpHTTPExporter exports over OTLP/HTTP with protobuf or JSON bodies
+type otlpHTTPExporter struct {
+	url     string
+	json    bool
+	headers map[string]string
+	client  *http.Client
+	stats   *httpStats
+}
+
+func newOTLPHTTPExporter(endpoint string, headers map[string]string) (*otlpHTTPExporter, error) {
+	tlsConfig, err := buildClientTLSConfig(otlpTLSCA, otlpTLSCert, otlpTLSKey, "", otlpTLSInsecure)
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/logs") {
+		url += "/v1/logs"
+	}
+	return &otlpHTTPExporter{
+		url:     url,
+		json:    otlpProtocol == "http/json",
+		headers: headers,
+		client:  newHTTPClient(otlpTimeout, tlsConfig, 1),
+		stats:   newHTTPStats(),
+	}, nil
+}
+
+func (e *otlpHTTPExporter) Export(req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	var body []byte
+	var err error
+	contentType := "application/x-protobuf"
+	if e.json {
+		contentType = "application/json"
+		// OTLP/JSON encodes enums as integers
+		body, err = protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(req)
+	} else {
+		body, err = proto.Marshal(req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	if otlpGzip {
+		if body, err = gzipBytes(body); err != nil {
+			return nil, fmt.Errorf("failed to compress request: %w", err)
+		}
+	}
+
+	code, respBody, err := doHTTPWithRetryIf(e.client, func() (*http.Request, error) {
+		r, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", contentType)
+		if otlpGzip {
+			r.Header.Set("Content-Encoding", "gzip")
+		}
+		for k, v := range e.headers {
+			r.Header.Set(k, v)
+		}
+		return r, nil
+	}, otlpMaxRetries, e.stats, otlpHTTPRetryable)
+	if err != nil {
+		return nil, err
+	}
+	if code < 200 || code >= 300 {
+		return nil, fmt.Errorf("export failed with status %d: %s", code, strings.TrimSpace(string(respBody)))
+	}
+
+	resp := &collogspb.ExportLogsServiceResponse{}
+	if len(respBody) > 0 {
+		if e.json {
+			err = protojson.Unmarshal(respBody, resp)
+		} else {
+			err = proto.Unmarshal(respBody, resp)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+func (e *otlpHTTPExporter) Print() {
+	e.stats.Print()
+}
+
+func (e *otlpHTTPExporter) Close() error {
+	return nil
+}
+
+func runOTLP(cmd *cobra.Command, args []string) error {
+	switch otlpProtocol {
+	case "grpc", "http/protobuf", "http/json":
+	default:
+		return fmt.Errorf("invalid protocol: %s (must be grpc, http/protobuf or http/json)", otlpProtocol)
+	}
+	if !generators.ValidEventType(otlpType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", otlpType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(otlpLEEFVersion, otlpLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if otlpBatchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	headers, err := parseKeyValues(otlpHeaders)
+	if err != nil {
+		return fmt.Errorf("invalid --headers: %w", err)
+	}
+
+	endpoint := otlpEndpoint
+	if endpoint == "" {
+		endpoint = otlpDefaultEndpoint(otlpProtocol)
+	}
+	var exporter otlpExporter
+	if otlpProtocol == "grpc" {
+		exporter, err = newOTLPGRPCExporter(endpoint, headers)
+	} else {
+		exporter, err = newOTLPHTTPExporter(endpoint, headers)
+	}
+	if err != nil {
+		return err
+	}
+	defer exporter.Close()
+
+	fmt.Printf("Exporting fake %s events as OTLP logs to %s (%s, batch size %d) at %d records/s\n",
+		otlpType, endpoint, otlpProtocol, otlpBatchSize, otlpRate)
+	if otlpCount > 0 {
+		fmt.Printf("Will send %d records total\n", otlpCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(otlpRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	generated := 0
+	dropped := 0
+	var rejected int64
+	startTime := time.Now()
+	opts := generators.Options{RFC: otlpRFC, LEEF: leef}
+	var batch []otlpRecord
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		defer func() { batch = batch[:0] }()
+
+		resp, err := exporter.Export(newOTLPRequest(batch, otlpServiceName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting, dropping %d records: %v\n", len(batch), err)
+			dropped += len(batch)
+			return
+		}
+		if n, msg := otlpRejected(resp); n > 0 {
+			rejected += n
+			fmt.Fprintf(os.Stderr, "Receiver rejected %d records: %s\n", n, msg)
+		}
+
+		before := sent
+		sent += len(batch)
+		if sent/1000 > before/1000 {
+			fmt.Printf("Sent %d records...\n", sent)
+		}
+	}
+	finish := func(format string) {
+		flush()
+		fmt.Printf(format, sent, time.Since(startTime))
+		if rejected > 0 || dropped > 0 {
+			fmt.Printf("Rejected by receiver: %d, dropped: %d\n", rejected, dropped)
+		}
+		exporter.Print()
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d records in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(otlpType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+			batch = append(batch, newOTLPRecord(otlpType, event, time.Now()))
+			generated++
+
+			if len(batch) >= otlpBatchSize {
+				flush()
+			}
+
+			if otlpCount > 0 && generated >= otlpCount {
+				finish("Completed. Sent %d records in %v\n")
+				return nil
+			}
+		}
+	}
+}
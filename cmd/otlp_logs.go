@@ -0,0 +1,157 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/bytefreezer/fakedata/generators"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// otlpSeverities maps syslog severities to OTel severity numbers, following
+// the collector's syslog parser (emerg=FATAL4 ... debug=DEBUG)
+var otlpSeverities = []logspb.SeverityNumber{
+	logspb.SeverityNumber_SEVERITY_NUMBER_FATAL4,
+	logspb.SeverityNumber_SEVERITY_NUMBER_FATAL3,
+	logspb.SeverityNumber_SEVERITY_NUMBER_FATAL2,
+	logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+	logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+	logspb.SeverityNumber_SEVERITY_NUMBER_INFO2,
+	logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+	logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG,
+}
+
+// otlpRecord is a log record with the resource it belongs to
+type otlpRecord struct {
+	hostname string
+	process  string
+	record   *logspb.LogRecord
+}
+
+func otlpString(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+// otlpValue converts a decoded JSON value to an AnyValue, keeping integers
+// as ints
+func otlpValue(v interface{}) *commonpb.AnyValue {
+	switch x := v.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: x}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: x}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: x}}
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(x)}}
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: x}}
+	}
+	b, _ := sonic.Marshal(v)
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: string(b)}}
+}
+
+// newOTLPRecord converts a generated event to a log record. Syslog-style
+// events get severity from their priority and syslog.* attributes; JSON
+// events get one attribute per field and their timestamp.
+func newOTLPRecord(eventType string, event []byte, now time.Time) otlpRecord {
+	rec := &logspb.LogRecord{
+		TimeUnixNano:         uint64(now.UnixNano()),
+		ObservedTimeUnixNano: uint64(now.UnixNano()),
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: string(event)}},
+	}
+	out := otlpRecord{record: rec}
+
+	if generators.IsJSONEventType(eventType) {
+		fields, err := decodeEvent(event)
+		if err != nil {
+			return out
+		}
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			rec.Attributes = append(rec.Attributes, &commonpb.KeyValue{Key: k, Value: otlpValue(fields[k])})
+		}
+		if ts, ok := eventTime(fields); ok {
+			rec.TimeUnixNano = uint64(ts.UnixNano())
+		}
+		out.process = eventField(fields, "process")
+		rec.SeverityNumber = logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+		rec.SeverityText = "info"
+		return out
+	}
+
+	rec.Attributes = append(rec.Attributes, otlpString("event.type", eventType))
+	if h, ok := generators.ParseSyslogHeader(string(event)); ok {
+		out.hostname = h.Hostname
+		out.process = h.AppName
+		rec.SeverityNumber = otlpSeverities[h.Severity()]
+		rec.SeverityText = severityNames[h.Severity()]
+		rec.Attributes = append(rec.Attributes,
+			&commonpb.KeyValue{Key: "syslog.facility", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(h.Facility())}}},
+			&commonpb.KeyValue{Key: "syslog.priority", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(h.Priority)}}},
+		)
+	}
+	return out
+}
+
+// newOTLPRequest groups records into one ResourceLogs per hostname/process
+// pair. service.name is the process, falling back to serviceName.
+func newOTLPRequest(records []otlpRecord, serviceName string) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	scopes := make(map[string]*logspb.ScopeLogs)
+	for _, r := range records {
+		key := r.hostname + "\x00" + r.process
+		scope, ok := scopes[key]
+		if !ok {
+			service := r.process
+			if service == "" {
+				service = serviceName
+			}
+			attrs := []*commonpb.KeyValue{otlpString("service.name", service)}
+			if r.hostname != "" {
+				attrs = append(attrs, otlpString("host.name", r.hostname))
+			}
+			if r.process != "" {
+				attrs = append(attrs, otlpString("process.executable.name", r.process))
+			}
+			scope = &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: "fakedata"}}
+			req.ResourceLogs = append(req.ResourceLogs, &logspb.ResourceLogs{
+				Resource:  &resourcepb.Resource{Attributes: attrs},
+				ScopeLogs: []*logspb.ScopeLogs{scope},
+			})
+			scopes[key] = scope
+		}
+		scope.LogRecords = append(scope.LogRecords, r.record)
+	}
+	return req
+}
+
+// otlpRejected returns the number of records the receiver rejected, with its
+// message, from a partial success response
+func otlpRejected(resp *collogspb.ExportLogsServiceResponse) (int64, string) {
+	if resp == nil || resp.PartialSuccess == nil {
+		return 0, ""
+	}
+	return resp.PartialSuccess.RejectedLogRecords, resp.PartialSuccess.ErrorMessage
+}
+
+// otlpDefaultEndpoint returns the standard collector endpoint for a protocol
+func otlpDefaultEndpoint(protocol string) string {
+	if protocol == "grpc" {
+		return "localhost:4317"
+	}
+	return "http://localhost:4318"
+}
@@ -0,0 +1,241 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/spf13/cobra"
+	"google.golang.org/api/option"
+)
+
+var pubsubProject string
+var pubsubTopic string
+var pubsubEmulatorHost string
+var pubsubCredentials string
+var pubsubCreateTopic bool
+var pubsubType string
+var pubsubRFC string
+var pubsubLEEFVersion string
+var pubsubLEEFDelimiter string
+var pubsubBatchCount int
+var pubsubBatchBytes int
+var pubsubBatchDelay time.Duration
+var pubsubOrderingKey string
+var pubsubAttributes string
+var pubsubAttributeFields string
+var pubsubRate int
+var pubsubCount int
+
+var pubsubCmd = &cobra.Command{
+	Use:   "pubsub",
+	Short: "Publish fake events to Google Cloud Pub/Sub (supports the emulator)",
+	Long: `Publish fake events to a Google Cloud Pub/Sub topic.
+
+The Pub/Sub emulator can run locally for fully offline testing:
+  gcloud beta emulators pubsub start --host-port=localhost:8085
+
+Pass --emulator-host (or set PUBSUB_EMULATOR_HOST) to use it; credentials are
+not needed then. Otherwise Application Default Credentials or --credentials
+are used.
+
+Publishes are batched by the client until --batch-count messages,
+--batch-bytes bytes or --batch-delay is reached. --ordering-key is a template
+({hostname}, {source_ip}, ...) that enables message ordering per key.
+
+Example:
+  fakedata pubsub --emulator-host localhost:8085 --project test --topic events --create-topic
+  fakedata pubsub --project my-project --topic events --batch-count 500 --batch-delay 50ms --rate 5000
+  fakedata pubsub --emulator-host localhost:8085 --project test --topic logs --type syslog \
+    --ordering-key "{hostname}" --attribute-fields hostname,severity --attributes env=test
+`,
+	RunE: runPubSub,
+}
+
+func init() {
+	pubsubCmd.Flags().StringVar(&pubsubProject, "project", "", "Google Cloud project ID (required)")
+	pubsubCmd.Flags().StringVar(&pubsubTopic, "topic", "", "Topic ID (required)")
+	pubsubCmd.Flags().StringVar(&pubsubEmulatorHost, "emulator-host", "", "Pub/Sub emulator host:port (overrides PUBSUB_EMULATOR_HOST)")
+	pubsubCmd.Flags().StringVar(&pubsubCredentials, "credentials", "", "Service account credentials file")
+	pubsubCmd.Flags().BoolVar(&pubsubCreateTopic, "create-topic", false, "Create the topic if it does not exist")
+	pubsubCmd.Flags().StringVar(&pubsubType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	pubsubCmd.Flags().StringVar(&pubsubRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	pubsubCmd.Flags().StringVar(&pubsubLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	pubsubCmd.Flags().StringVar(&pubsubLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	pubsubCmd.Flags().IntVar(&pubsubBatchCount, "batch-count", pubsub.DefaultPublishSettings.CountThreshold, "Messages per publish batch")
+	pubsubCmd.Flags().IntVar(&pubsubBatchBytes, "batch-bytes", pubsub.DefaultPublishSettings.ByteThreshold, "Bytes per publish batch")
+	pubsubCmd.Flags().DurationVar(&pubsubBatchDelay, "batch-delay", pubsub.DefaultPublishSettings.DelayThreshold, "Maximum time to wait before publishing a batch")
+	pubsubCmd.Flags().StringVar(&pubsubOrderingKey, "ordering-key", "", "Ordering key template (enables message ordering)")
+	pubsubCmd.Flags().StringVar(&pubsubAttributes, "attributes", "", "Static message attributes, e.g. env=test,team=data")
+	pubsubCmd.Flags().StringVar(&pubsubAttributeFields, "attribute-fields", "", "Event fields to copy into message attributes, comma-separated")
+	pubsubCmd.Flags().IntVar(&pubsubRate, "rate", 10, "Messages per second")
+	pubsubCmd.Flags().IntVar(&pubsubCount, "count", 0, "Total messages to send (0 = unlimited)")
+}
+
+func runPubSub(cmd *cobra.Command, args []string) error {
+	if pubsubProject == "" || pubsubTopic == "" {
+		return fmt.Errorf("--project and --topic are required")
+	}
+	if !generators.ValidEventType(pubsubType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", pubsubType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(pubsubLEEFVersion, pubsubLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+
+	staticAttrs, err := parseKeyValues(pubsubAttributes)
+	if err != nil {
+		return fmt.Errorf("invalid --attributes: %w", err)
+	}
+	var attrFields []string
+	for _, f := range strings.Split(pubsubAttributeFields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			attrFields = append(attrFields, f)
+		}
+	}
+
+	// The client library switches to the emulator when this is set
+	if pubsubEmulatorHost != "" {
+		os.Setenv("PUBSUB_EMULATOR_HOST", pubsubEmulatorHost)
+	}
+	var clientOpts []option.ClientOption
+	if pubsubCredentials != "" {
+		clientOpts = append(clientOpts, option.WithCredentialsFile(pubsubCredentials))
+	}
+
+	ctx := context.Background()
+	client, err := pubsub.NewClient(ctx, pubsubProject, clientOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+	defer client.Close()
+	if host := os.Getenv("PUBSUB_EMULATOR_HOST"); host != "" {
+		fmt.Printf("Using Pub/Sub emulator: %s\n", host)
+	}
+
+	topic := client.Topic(pubsubTopic)
+	if pubsubCreateTopic {
+		exists, err := topic.Exists(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check topic %s: %w", pubsubTopic, err)
+		}
+		if !exists {
+			if topic, err = client.CreateTopic(ctx, pubsubTopic); err != nil {
+				return fmt.Errorf("failed to create topic %s: %w", pubsubTopic, err)
+			}
+			fmt.Printf("Created topic: %s\n", pubsubTopic)
+		}
+	}
+	topic.PublishSettings.CountThreshold = pubsubBatchCount
+	topic.PublishSettings.ByteThreshold = pubsubBatchBytes
+	topic.PublishSettings.DelayThreshold = pubsubBatchDelay
+	topic.EnableMessageOrdering = pubsubOrderingKey != ""
+	defer topic.Stop()
+
+	fmt.Printf("Publishing fake %s events to projects/%s/topics/%s at %d msg/s\n",
+		pubsubType, pubsubProject, pubsubTopic, pubsubRate)
+	fmt.Printf("Batching: %d messages, %d bytes, %v\n", pubsubBatchCount, pubsubBatchBytes, pubsubBatchDelay)
+	if topic.EnableMessageOrdering {
+		fmt.Printf("Ordering key: %s\n", pubsubOrderingKey)
+	}
+	if pubsubCount > 0 {
+		fmt.Printf("Will send %d messages total\n", pubsubCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(pubsubRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	generated := 0
+	var published, failed atomic.Int64
+	var results sync.WaitGroup
+	latency := &latencyStats{}
+	startTime := time.Now()
+	opts := generators.Options{RFC: pubsubRFC, LEEF: leef}
+
+	finish := func(format string) {
+		topic.Flush()
+		results.Wait()
+		fmt.Printf(format, published.Load(), time.Since(startTime))
+		if n := failed.Load(); n > 0 {
+			fmt.Printf("Failed publishes: %d\n", n)
+		}
+		latency.Print("Publish latency")
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d messages in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(pubsubType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+
+			msg := &pubsub.Message{Data: event}
+			if len(staticAttrs) > 0 || len(attrFields) > 0 || topic.EnableMessageOrdering {
+				values := eventTemplateValues(pubsubType, event)
+				msg.Attributes = make(map[string]string, len(staticAttrs)+len(attrFields))
+				for k, v := range staticAttrs {
+					msg.Attributes[k] = v
+				}
+				for _, f := range attrFields {
+					if v := values[f]; v != "" {
+						msg.Attributes[f] = v
+					}
+				}
+				if topic.EnableMessageOrdering {
+					msg.OrderingKey = expandTemplate(pubsubOrderingKey, values)
+				}
+			}
+
+			start := time.Now()
+			result := topic.Publish(ctx, msg)
+			results.Add(1)
+			go func(key string) {
+				defer results.Done()
+				if _, err := result.Get(ctx); err != nil {
+					if failed.Add(1) <= 10 {
+						fmt.Fprintf(os.Stderr, "Error publishing: %v\n", err)
+					}
+					// A failed ordered publish pauses its key until resumed
+					if key != "" {
+						topic.ResumePublish(key)
+					}
+					return
+				}
+				latency.record(time.Since(start))
+				if n := published.Add(1); n%1000 == 0 {
+					fmt.Printf("Sent %d messages...\n", n)
+				}
+			}(msg.OrderingKey)
+			generated++
+
+			if pubsubCount > 0 && generated >= pubsubCount {
+				finish("Completed. Sent %d messages in %v\n")
+				return nil
+			}
+		}
+	}
+}
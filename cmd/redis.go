@@ -0,0 +1,289 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bytefreezer/fakedata/generators"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+)
+
+var redisAddr string
+var redisDB int
+var redisUser string
+var redisPassword string
+var redisMode string
+var redisStream string
+var redisMaxLen int64
+var redisApprox bool
+var redisEncoding string
+var redisField string
+var redisChannel string
+var redisPipeline int
+var redisType string
+var redisRFC string
+var redisLEEFVersion string
+var redisLEEFDelimiter string
+var redisTLS bool
+var redisTLSCA string
+var redisTLSCert string
+var redisTLSKey string
+var redisTLSInsecure bool
+var redisRate int
+var redisCount int
+
+var redisCmd = &cobra.Command{
+	Use:   "redis",
+	Short: "Send fake events to Redis Streams or Pub/Sub",
+	Long: `Send fake events to Redis with XADD (streams) or PUBLISH (pub/sub).
+
+Redis can run locally for testing:
+  docker run -p 6379:6379 redis:latest
+
+Modes:
+  stream   XADD to --stream, optionally trimmed with MAXLEN
+  publish  PUBLISH to --channel
+
+Stream encodings:
+  fields   One stream field per event field (JSON events), or message plus
+           hostname/process/severity for syslog-style events
+  blob     The whole event in a single field (--field)
+
+--stream and --channel accept {name} placeholders like the mqtt topic.
+--pipeline N sends N commands per round trip.
+
+Example:
+  fakedata redis --addr localhost:6379 --stream events --maxlen 100000 --rate 1000
+  fakedata redis --stream "logs:{hostname}" --type syslog --encoding blob --pipeline 50
+  fakedata redis --mode publish --channel "fakedata.{type}" --rate 100
+  fakedata redis --addr redis:6380 --tls --tls-ca ca.pem --user default --password s3cret
+`,
+	RunE: runRedis,
+}
+
+func init() {
+	redisCmd.Flags().StringVar(&redisAddr, "addr", "localhost:6379", "Redis address (host:port)")
+	redisCmd.Flags().IntVar(&redisDB, "db", 0, "Database number")
+	redisCmd.Flags().StringVar(&redisUser, "user", "", "ACL username")
+	redisCmd.Flags().StringVar(&redisPassword, "password", "", "Password")
+	redisCmd.Flags().StringVar(&redisMode, "mode", "stream", "Mode: stream or publish")
+	redisCmd.Flags().StringVar(&redisStream, "stream", "fakedata", "Stream key template for stream mode")
+	redisCmd.Flags().Int64Var(&redisMaxLen, "maxlen", 0, "Trim streams to about this many entries (0 = no trimming)")
+	redisCmd.Flags().BoolVar(&redisApprox, "approx", true, "Use approximate (~) MAXLEN trimming")
+	redisCmd.Flags().StringVar(&redisEncoding, "encoding", "fields", "Stream entry encoding: fields or blob")
+	redisCmd.Flags().StringVar(&redisField, "field", "data", "Field name for blob encoding")
+	redisCmd.Flags().StringVar(&redisChannel, "channel", "fakedata", "Channel template for publish mode")
+	redisCmd.Flags().IntVar(&redisPipeline, "pipeline", 1, "Commands per pipeline round trip")
+	redisCmd.Flags().StringVar(&redisType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	redisCmd.Flags().StringVar(&redisRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	redisCmd.Flags().StringVar(&redisLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	redisCmd.Flags().StringVar(&redisLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	redisCmd.Flags().BoolVar(&redisTLS, "tls", false, "Connect with TLS")
+	redisCmd.Flags().StringVar(&redisTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	redisCmd.Flags().StringVar(&redisTLSCert, "tls-cert", "", "Client certificate file (mTLS)")
+	redisCmd.Flags().StringVar(&redisTLSKey, "tls-key", "", "Client private key file (mTLS)")
+	redisCmd.Flags().BoolVar(&redisTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	redisCmd.Flags().IntVar(&redisRate, "rate", 10, "Messages per second")
+	redisCmd.Flags().IntVar(&redisCount, "count", 0, "Total messages to send (0 = unlimited)")
+}
+
+// redisStreamValues returns the XADD field/value pairs for one event
+func redisStreamValues(event []byte) interface{} {
+	if redisEncoding == "blob" {
+		return []string{redisField, string(event)}
+	}
+	values := eventFieldValues(redisType, event)
+	if !generators.IsJSONEventType(redisType) {
+		values["message"] = string(event)
+	}
+	// Sort fields so entries have a stable layout
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k, values[k])
+	}
+	return pairs
+}
+
+func runRedis(cmd *cobra.Command, args []string) error {
+	if redisMode != "stream" && redisMode != "publish" {
+		return fmt.Errorf("invalid mode: %s (must be stream or publish)", redisMode)
+	}
+	if redisEncoding != "fields" && redisEncoding != "blob" {
+		return fmt.Errorf("invalid encoding: %s (must be fields or blob)", redisEncoding)
+	}
+	if !generators.ValidEventType(redisType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", redisType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(redisLEEFVersion, redisLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+	if redisPipeline < 1 {
+		return fmt.Errorf("--pipeline must be at least 1")
+	}
+
+	options := &redis.Options{
+		Addr:     redisAddr,
+		DB:       redisDB,
+		Username: redisUser,
+		Password: redisPassword,
+	}
+	if redisTLS {
+		host := redisAddr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		tlsConfig, err := buildClientTLSConfig(redisTLSCA, redisTLSCert, redisTLSKey, host, redisTLSInsecure)
+		if err != nil {
+			return err
+		}
+		options.TLSConfig = tlsConfig
+	}
+
+	ctx := context.Background()
+	client := redis.NewClient(options)
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to connect to Redis at %s: %w", redisAddr, err)
+	}
+
+	target := redisStream
+	if redisMode == "publish" {
+		target = redisChannel
+	}
+	fmt.Printf("Sending fake %s events to Redis %s (%s %s) at %d msg/s\n", redisType, redisAddr, redisMode, target, redisRate)
+	if redisMode == "stream" && redisMaxLen > 0 {
+		fmt.Printf("Trimming streams to MAXLEN %d\n", redisMaxLen)
+	}
+	if redisPipeline > 1 {
+		fmt.Printf("Pipelining %d commands per round trip\n", redisPipeline)
+	}
+	if redisCount > 0 {
+		fmt.Printf("Will send %d messages total\n", redisCount)
+	} else {
+		fmt.Println("Press Ctrl+C to stop")
+	}
+
+	// Setup signal handler
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	interval := time.Second / time.Duration(redisRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sent := 0
+	generated := 0
+	failed := 0
+	var receivers int64
+	startTime := time.Now()
+	opts := generators.Options{RFC: redisRFC, LEEF: leef}
+	streams := make(map[string]bool)
+	pipe := client.Pipeline()
+	queued := 0
+
+	flush := func() {
+		if queued == 0 {
+			return
+		}
+		cmds, _ := pipe.Exec(ctx)
+		queued = 0
+		before := sent
+		for _, c := range cmds {
+			if err := c.Err(); err != nil {
+				failed++
+				if failed <= 10 {
+					fmt.Fprintf(os.Stderr, "Error sending: %v\n", err)
+				}
+				continue
+			}
+			if ic, ok := c.(*redis.IntCmd); ok {
+				receivers += ic.Val()
+			}
+			sent++
+		}
+		if sent/1000 > before/1000 {
+			fmt.Printf("Sent %d messages...\n", sent)
+		}
+	}
+	finish := func(format string) {
+		flush()
+		fmt.Printf(format, sent, time.Since(startTime))
+		if failed > 0 {
+			fmt.Printf("Failed commands: %d\n", failed)
+		}
+		if redisMode == "publish" {
+			fmt.Printf("Total subscriber deliveries: %d\n", receivers)
+			return
+		}
+		keys := make([]string, 0, len(streams))
+		for k := range streams {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Println("Stream lengths:")
+		for i, k := range keys {
+			if i == 10 {
+				fmt.Printf("  ... %d more streams\n", len(keys)-i)
+				break
+			}
+			if n, err := client.XLen(ctx, k).Result(); err == nil {
+				fmt.Printf("  %s: %d entries\n", k, n)
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-sigChan:
+			finish("\nStopped. Sent %d messages in %v\n")
+			return nil
+		case <-ticker.C:
+			event, err := generators.GenerateEvent(redisType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
+				continue
+			}
+
+			if redisMode == "publish" {
+				channel := expandTemplate(redisChannel, eventTemplateValues(redisType, event))
+				pipe.Publish(ctx, channel, event)
+			} else {
+				key := expandTemplate(redisStream, eventTemplateValues(redisType, event))
+				streams[key] = true
+				pipe.XAdd(ctx, &redis.XAddArgs{
+					Stream: key,
+					MaxLen: redisMaxLen,
+					Approx: redisApprox && redisMaxLen > 0,
+					Values: redisStreamValues(event),
+				})
+			}
+			queued++
+			generated++
+
+			if queued >= redisPipeline {
+				flush()
+			}
+
+			if redisCount > 0 && generated >= redisCount {
+				finish("Completed. Sent %d messages in %v\n")
+				return nil
+			}
+		}
+	}
+}
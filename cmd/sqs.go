@@ -6,14 +6,19 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bytedance/sonic"
 	"github.com/bytefreezer/fakedata/generators"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +28,17 @@ var sqsRegion string
 var sqsEndpoint string
 var sqsRate int
 var sqsCount int
+var sqsBatchSize int
+var sqsMaxRetries int
+var sqsFIFO bool
+var sqsGroupIDField string
+var sqsGroupCount int
+var sqsDedupIDField string
+var sqsAttributes string
+var sqsAttributeFields string
+var sqsDelaySeconds int
+var sqsCreateQueue bool
+var sqsQueueName string
 
 var sqsCmd = &cobra.Command{
 	Use:   "sqs",
@@ -39,25 +55,202 @@ Example:
 
   # AWS
   fakedata sqs --queue-url https://sqs.us-east-1.amazonaws.com/123456789/my-queue --region us-east-1
+
+  # Batches of 10 with message attributes taken from event fields
+  fakedata sqs --queue-url ... --batch-size 10 --attribute-fields process,action --attributes env=test
+
+  # Create a FIFO queue on LocalStack and group messages by source IP
+  fakedata sqs --endpoint http://localhost:4566 --create-queue --queue-name events.fifo \
+    --group-id-field source_ip --dedup-id-field session_id
+
+FIFO queues are detected from the ".fifo" suffix or forced with --fifo. The
+message group ID comes from --group-id-field, or one of --group-count generated
+groups; the deduplication ID comes from --dedup-id-field or is generated.
 `,
 	RunE: runSQS,
 }
 
 func init() {
-	sqsCmd.Flags().StringVar(&sqsQueueURL, "queue-url", "", "SQS queue URL (required)")
+	sqsCmd.Flags().StringVar(&sqsQueueURL, "queue-url", "", "SQS queue URL (required unless --create-queue)")
 	sqsCmd.Flags().StringVar(&sqsRegion, "region", "us-east-1", "AWS region")
 	sqsCmd.Flags().StringVar(&sqsEndpoint, "endpoint", "", "Custom endpoint URL (for LocalStack)")
 	sqsCmd.Flags().IntVar(&sqsRate, "rate", 10, "Messages per second")
 	sqsCmd.Flags().IntVar(&sqsCount, "count", 0, "Total messages to send (0 = unlimited)")
-	sqsCmd.MarkFlagRequired("queue-url")
+	sqsCmd.Flags().IntVar(&sqsBatchSize, "batch-size", 1, "Messages per SendMessageBatch call (1-10)")
+	sqsCmd.Flags().IntVar(&sqsMaxRetries, "max-retries", 3, "Retries for failed batch entries")
+	sqsCmd.Flags().BoolVar(&sqsFIFO, "fifo", false, "Send as FIFO messages (auto-detected from .fifo suffix)")
+	sqsCmd.Flags().StringVar(&sqsGroupIDField, "group-id-field", "", "Event field used as MessageGroupId (default: generated)")
+	sqsCmd.Flags().IntVar(&sqsGroupCount, "group-count", 10, "Number of generated message groups when --group-id-field is unset")
+	sqsCmd.Flags().StringVar(&sqsDedupIDField, "dedup-id-field", "", "Event field used as MessageDeduplicationId (default: generated)")
+	sqsCmd.Flags().StringVar(&sqsAttributes, "attributes", "", "Static message attributes, e.g. env=test,team=data")
+	sqsCmd.Flags().StringVar(&sqsAttributeFields, "attribute-fields", "", "Event fields to copy into message attributes, comma-separated")
+	sqsCmd.Flags().IntVar(&sqsDelaySeconds, "delay-seconds", 0, "Per-message delay in seconds (0-900, standard queues only)")
+	sqsCmd.Flags().BoolVar(&sqsCreateQueue, "create-queue", false, "Create the queue before sending (e.g. on LocalStack)")
+	sqsCmd.Flags().StringVar(&sqsQueueName, "queue-name", "", "Queue name for --create-queue (default: last segment of --queue-url)")
+}
+
+// parseKeyValues parses "k1=v1,k2=v2" into a map
+func parseKeyValues(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid key=value pair: %q", pair)
+		}
+		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return out, nil
+}
+
+// sqsCreateQueueURL creates the queue (FIFO if requested) and returns its URL
+func sqsCreateQueueURL(ctx context.Context, client *sqs.Client, name string, fifo bool) (string, error) {
+	input := &sqs.CreateQueueInput{QueueName: aws.String(name)}
+	if fifo {
+		input.Attributes = map[string]string{"FifoQueue": "true"}
+	}
+	out, err := client.CreateQueue(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create queue %s: %w", name, err)
+	}
+	return aws.ToString(out.QueueUrl), nil
+}
+
+// sqsBatchEntry builds a batch entry from a generated event
+func sqsBatchEntry(id int, event map[string]interface{}, body []byte, fifo bool, staticAttrs map[string]string, attrFields []string) types.SendMessageBatchRequestEntry {
+	entry := types.SendMessageBatchRequestEntry{
+		Id:          aws.String(strconv.Itoa(id)),
+		MessageBody: aws.String(string(body)),
+	}
+
+	attrs := make(map[string]types.MessageAttributeValue)
+	for k, v := range staticAttrs {
+		attrs[k] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+	}
+	for _, f := range attrFields {
+		if v := eventField(event, f); v != "" {
+			attrs[f] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+		}
+	}
+	if len(attrs) > 0 {
+		entry.MessageAttributes = attrs
+	}
+
+	if fifo {
+		groupID := eventField(event, sqsGroupIDField)
+		if groupID == "" {
+			groupID = fmt.Sprintf("group-%d", rand.Intn(sqsGroupCount))
+		}
+		dedupID := eventField(event, sqsDedupIDField)
+		if dedupID == "" {
+			dedupID = fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int63())
+		}
+		entry.MessageGroupId = aws.String(groupID)
+		entry.MessageDeduplicationId = aws.String(dedupID)
+	} else {
+		entry.DelaySeconds = int32(sqsDelaySeconds)
+	}
+
+	return entry
+}
+
+// sqsSendBatch sends entries with SendMessageBatch, retrying entries that
+// failed through no fault of the sender. Returns the number delivered.
+func sqsSendBatch(ctx context.Context, client *sqs.Client, queueURL string, entries []types.SendMessageBatchRequestEntry) int {
+	delivered := 0
+	pending := entries
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		out, err := client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries:  pending,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending batch: %v\n", err)
+			if attempt >= sqsMaxRetries {
+				return delivered
+			}
+			time.Sleep(time.Duration(attempt+1) * 200 * time.Millisecond)
+			continue
+		}
+
+		delivered += len(out.Successful)
+		if len(out.Failed) == 0 {
+			return delivered
+		}
+
+		byID := make(map[string]types.SendMessageBatchRequestEntry, len(pending))
+		for _, e := range pending {
+			byID[aws.ToString(e.Id)] = e
+		}
+
+		var retry []types.SendMessageBatchRequestEntry
+		for _, f := range out.Failed {
+			fmt.Fprintf(os.Stderr, "Entry %s failed: %s: %s\n", aws.ToString(f.Id), aws.ToString(f.Code), aws.ToString(f.Message))
+			if !f.SenderFault {
+				retry = append(retry, byID[aws.ToString(f.Id)])
+			}
+		}
+		if attempt >= sqsMaxRetries {
+			if len(retry) > 0 {
+				fmt.Fprintf(os.Stderr, "Giving up on %d entries after %d retries\n", len(retry), sqsMaxRetries)
+			}
+			return delivered
+		}
+		pending = retry
+		if len(pending) > 0 {
+			time.Sleep(time.Duration(attempt+1) * 200 * time.Millisecond)
+		}
+	}
+
+	return delivered
 }
 
 func runSQS(cmd *cobra.Command, args []string) error {
+	if sqsBatchSize < 1 || sqsBatchSize > 10 {
+		return fmt.Errorf("invalid batch size: %d (must be 1-10)", sqsBatchSize)
+	}
+	if sqsDelaySeconds < 0 || sqsDelaySeconds > 900 {
+		return fmt.Errorf("invalid delay: %d (must be 0-900 seconds)", sqsDelaySeconds)
+	}
+	if sqsGroupCount < 1 {
+		return fmt.Errorf("invalid group count: %d (must be at least 1)", sqsGroupCount)
+	}
+
+	queueName := sqsQueueName
+	if queueName == "" && sqsQueueURL != "" {
+		queueName = sqsQueueURL[strings.LastIndex(sqsQueueURL, "/")+1:]
+	}
+	if sqsQueueURL == "" && !(sqsCreateQueue && queueName != "") {
+		return fmt.Errorf("--queue-url is required (or --create-queue with --queue-name)")
+	}
+
+	fifo := sqsFIFO || strings.HasSuffix(queueName, ".fifo")
+	if fifo && sqsDelaySeconds > 0 {
+		return fmt.Errorf("--delay-seconds is not supported per message on FIFO queues")
+	}
+
+	staticAttrs, err := parseKeyValues(sqsAttributes)
+	if err != nil {
+		return fmt.Errorf("invalid --attributes: %w", err)
+	}
+	var attrFields []string
+	for _, f := range strings.Split(sqsAttributeFields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			attrFields = append(attrFields, f)
+		}
+	}
+	if len(staticAttrs)+len(attrFields) > 10 {
+		return fmt.Errorf("too many message attributes: %d (SQS allows 10)", len(staticAttrs)+len(attrFields))
+	}
+
 	ctx := context.Background()
 
 	// Load AWS config
 	var cfg aws.Config
-	var err error
 
 	if sqsEndpoint != "" {
 		// Custom endpoint (LocalStack)
@@ -88,8 +281,20 @@ func runSQS(cmd *cobra.Command, args []string) error {
 		client = sqs.NewFromConfig(cfg)
 	}
 
-	fmt.Printf("Sending fake JSON to SQS queue at %d msg/s\n", sqsRate)
-	fmt.Printf("Queue URL: %s\n", sqsQueueURL)
+	queueURL := sqsQueueURL
+	if sqsCreateQueue {
+		queueURL, err = sqsCreateQueueURL(ctx, client, queueName, fifo)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created queue: %s\n", queueName)
+	}
+
+	fmt.Printf("Sending fake JSON to SQS queue at %d msg/s (batch size %d)\n", sqsRate, sqsBatchSize)
+	fmt.Printf("Queue URL: %s\n", queueURL)
+	if fifo {
+		fmt.Println("FIFO mode: setting MessageGroupId and MessageDeduplicationId")
+	}
 	if sqsCount > 0 {
 		fmt.Printf("Will send %d messages total\n", sqsCount)
 	} else {
@@ -106,34 +311,44 @@ func runSQS(cmd *cobra.Command, args []string) error {
 
 	sent := 0
 	startTime := time.Now()
+	batch := make([]types.SendMessageBatchRequestEntry, 0, sqsBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		before := sent
+		sent += sqsSendBatch(ctx, client, queueURL, batch)
+		batch = batch[:0]
+		if sent/1000 > before/1000 {
+			fmt.Printf("Sent %d messages...\n", sent)
+		}
+	}
 
+	generated := 0
 	for {
 		select {
 		case <-sigChan:
+			flush()
 			fmt.Printf("\nStopped. Sent %d messages in %v\n", sent, time.Since(startTime))
 			return nil
 		case <-ticker.C:
-			event, err := generators.GenerateJSONEvent()
+			event := generators.NewJSONEvent()
+			body, err := sonic.Marshal(event)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
 				continue
 			}
 
-			_, err = client.SendMessage(ctx, &sqs.SendMessageInput{
-				QueueUrl:    aws.String(sqsQueueURL),
-				MessageBody: aws.String(string(event)),
-			})
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error sending: %v\n", err)
-				continue
-			}
+			batch = append(batch, sqsBatchEntry(len(batch), event, body, fifo, staticAttrs, attrFields))
+			generated++
 
-			sent++
-			if sent%1000 == 0 {
-				fmt.Printf("Sent %d messages...\n", sent)
+			done := sqsCount > 0 && generated >= sqsCount
+			if len(batch) >= sqsBatchSize || done {
+				flush()
 			}
 
-			if sqsCount > 0 && sent >= sqsCount {
+			if done {
 				fmt.Printf("Completed. Sent %d messages in %v\n", sent, time.Since(startTime))
 				return nil
 			}
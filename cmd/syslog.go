@@ -4,10 +4,12 @@
 package cmd
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -20,18 +22,98 @@ var syslogPort int
 var syslogRate int
 var syslogCount int
 var syslogRFC string
+var syslogLEEFVersion string
+var syslogLEEFDelimiter string
 var syslogType string
+var syslogTransport string
+var syslogFraming string
+var syslogTrailer string
+var syslogConnections int
+var syslogConnMaxMessages int
+var syslogReconnectInterval time.Duration
+var syslogMaxRetries int
+var syslogTLSCA string
+var syslogTLSCert string
+var syslogTLSKey string
+var syslogTLSServerName string
+var syslogTLSInsecure bool
+var syslogBOM bool
+var syslogFacility string
+var syslogSeverity string
+var syslogHeaders string
+var syslogRELPWindow int
+var syslogRELPTimeout time.Duration
 
 var syslogCmd = &cobra.Command{
 	Use:   "syslog",
-	Short: "Send fake syslog messages over UDP",
-	Long: `Send fake syslog messages over UDP to test syslog ingestion.
+	Short: "Send fake syslog messages over UDP, TCP, TLS or RELP",
+	Long: `Send fake syslog messages over UDP, TCP, TLS or RELP to test syslog ingestion.
 
-Supports both RFC3164 and RFC5424 formats, with multiple message types:
+Supports both RFC3164 and RFC5424 formats for every message type:
   generic   - Standard auth/system messages (default)
   tms       - DDoS mitigation system logs (blocked_host events)
   firewall  - UFW/iptables style firewall logs
   ids       - Snort/Suricata IDS alert format
+  multiline - Stack traces, pretty JSON and indented logs in one message; pick
+              a kind with multiline-java, -python, -go, -json or -indent
+  cef-*     - ArcSight CEF:0 events from the firewall, ids, tms or generic
+              JSON generators: cef-firewall, cef-ids, cef-tms, cef-json
+  leef-*    - IBM QRadar LEEF events from the same generators: leef-firewall,
+              leef-ids, leef-tms, leef-json (--leef-version, --leef-delimiter)
+  asa, panos, fortigate, checkpoint, srx
+            - Vendor firewall dialects: Cisco ASA, PAN-OS CSV, FortiGate
+              key=value, Check Point Log Exporter/LEA, Juniper SRX RT_FLOW
+  eve       - Suricata EVE JSON, as eve-log with filetype syslog sends it; pick
+              one event type with eve-alert, -flow, -dns, -http, -tls,
+              -fileinfo or -anomaly
+
+RFC5424 messages carry a MSGID per event kind and structured data: a
+type-specific element (event@32473, tms@32473, fw@32473, ids@32473,
+multiline@32473, asa@32473, panos@32473, fgt@32473 or eve@32473), origin and
+meta sequenceId. Check Point and Juniper SRX messages carry their vendor's own
+element instead (sc@2620, junos@2636.1.1.1.2.129), and CEF and LEEF messages
+carry no structured data. --bom prefixes MSG with a UTF-8 byte order mark.
+
+--facility and --severity replace each type's default priority with weighted
+mixes of keywords (kern, user, auth, local0 ... / emerg ... debug) or numbers;
+a name without a weight counts as 1. --header-variants mixes in the header
+quirks of real devices:
+  standard     - plain RFC3164/RFC5424 header
+  no-hostname  - hostname omitted ("-" in RFC5424)
+  iso          - RFC3339 timestamp in an RFC3164 header
+  year         - "Oct 18 2026 21:36:56" timestamp (RFC3164)
+  tz           - zone suffix such as "CET" (RFC3164) or a non-UTC offset (RFC5424)
+
+Transports:
+  udp       - One message per datagram (default)
+  tcp       - RFC 6587 framing over a pool of TCP connections
+  tls       - RFC 5425 syslog over TLS (port 6514 unless --port is set)
+  relp      - rsyslog RELP with acknowledgements (port 2514 unless --port is set)
+  relp-tls  - RELP over TLS
+
+TCP and TLS framing:
+  octet-counting   - "<length> <message>" (default)
+  non-transparent  - message followed by --trailer (lf, crlf or nul)
+
+Multiline messages keep their line breaks. Use octet-counting, RELP or a nul
+trailer to deliver them intact; with an lf trailer every line arrives as a
+separate message.
+
+Broken TCP connections are re-established every --reconnect-interval, up to
+--max-retries times per message. --conn-max-messages closes and reopens each
+connection after that many messages to exercise receiver connection handling.
+
+TLS verifies the server against --tls-ca (or the system roots) using --host or
+--tls-server-name as SNI. --tls-cert and --tls-key present a client certificate
+for mTLS. "fakedata certs" generates a throwaway CA and certificates for this.
+Under TLS 1.3 a rejected client certificate surfaces as reset connections
+after the handshake rather than as a dial error.
+
+RELP keeps up to --relp-window messages unacknowledged. When the session
+breaks, the unacknowledged messages are retransmitted on the next session, so
+the receiver sees each message at least once. The session is closed with the
+RELP close handshake after outstanding acks arrive. --relp-timeout bounds the
+wait for each response.
 
 Example:
   fakedata syslog --host 127.0.0.1 --port 514 --rfc 3164
@@ -39,6 +121,15 @@ Example:
   fakedata syslog --host 127.0.0.1 --port 514 --type tms --rate 100
   fakedata syslog --host 127.0.0.1 --port 514 --type firewall
   fakedata syslog --host 127.0.0.1 --port 514 --type ids
+  fakedata syslog --host 127.0.0.1 --port 514 --type firewall --rfc 5424 --bom
+  fakedata syslog --host 127.0.0.1 --port 601 --transport tcp --type multiline-java --rfc 5424
+  fakedata syslog --host 127.0.0.1 --port 514 --facility auth:3,authpriv,local4 --severity info:8,warning:2,err:1 \
+    --header-variants standard:85,no-hostname:5,iso:5,year:3,tz:2
+  fakedata syslog --host 127.0.0.1 --port 601 --transport tcp --rfc 5424
+  fakedata syslog --host 127.0.0.1 --port 514 --transport tcp --framing non-transparent --connections 4
+  fakedata syslog --host localhost --transport tls --tls-ca certs/ca.pem \
+    --tls-cert certs/client.pem --tls-key certs/client-key.pem
+  fakedata syslog --host 127.0.0.1 --transport relp --relp-window 512 --rate 1000
 `,
 	RunE: runSyslog,
 }
@@ -49,7 +140,118 @@ func init() {
 	syslogCmd.Flags().IntVar(&syslogRate, "rate", 10, "Messages per second")
 	syslogCmd.Flags().IntVar(&syslogCount, "count", 0, "Total messages to send (0 = unlimited)")
 	syslogCmd.Flags().StringVar(&syslogRFC, "rfc", "3164", "Syslog RFC format (3164 or 5424)")
-	syslogCmd.Flags().StringVar(&syslogType, "type", "generic", "Message type: generic, tms, firewall, ids")
+	syslogCmd.Flags().StringVar(&syslogLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	syslogCmd.Flags().StringVar(&syslogLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
+	syslogCmd.Flags().StringVar(&syslogType, "type", "generic", "Message type: "+strings.Join(generators.SyslogTypes, ", "))
+	syslogCmd.Flags().BoolVar(&syslogBOM, "bom", false, "Prefix RFC5424 MSG with a UTF-8 BOM")
+	syslogCmd.Flags().StringVar(&syslogFacility, "facility", "", "Weighted facility mix, e.g. auth:3,local0:1 (default: per type)")
+	syslogCmd.Flags().StringVar(&syslogSeverity, "severity", "", "Weighted severity mix, e.g. info:6,warning:3,err:1 (default: per type)")
+	syslogCmd.Flags().StringVar(&syslogHeaders, "header-variants", "", "Weighted header variant mix: standard, no-hostname, iso, year, tz")
+	syslogCmd.Flags().StringVar(&syslogTransport, "transport", "udp", "Transport: udp, tcp, tls, relp or relp-tls")
+	syslogCmd.Flags().StringVar(&syslogFraming, "framing", "octet-counting", "TCP/TLS framing: octet-counting or non-transparent")
+	syslogCmd.Flags().StringVar(&syslogTrailer, "trailer", "lf", "Non-transparent framing trailer: lf, crlf or nul")
+	syslogCmd.Flags().IntVar(&syslogConnections, "connections", 1, "TCP connections to spread messages over")
+	syslogCmd.Flags().IntVar(&syslogConnMaxMessages, "conn-max-messages", 0, "Reopen each TCP connection after this many messages (0 = never)")
+	syslogCmd.Flags().DurationVar(&syslogReconnectInterval, "reconnect-interval", time.Second, "Delay between TCP reconnect attempts")
+	syslogCmd.Flags().IntVar(&syslogMaxRetries, "max-retries", 3, "TCP reconnect attempts per message before dropping it")
+	syslogCmd.Flags().StringVar(&syslogTLSCA, "tls-ca", "", "CA certificate file for verifying the server")
+	syslogCmd.Flags().StringVar(&syslogTLSCert, "tls-cert", "", "Client certificate file (mTLS)")
+	syslogCmd.Flags().StringVar(&syslogTLSKey, "tls-key", "", "Client private key file (mTLS)")
+	syslogCmd.Flags().StringVar(&syslogTLSServerName, "tls-server-name", "", "Server name for SNI and verification (default: --host)")
+	syslogCmd.Flags().BoolVar(&syslogTLSInsecure, "tls-insecure", false, "Skip server certificate verification")
+	syslogCmd.Flags().IntVar(&syslogRELPWindow, "relp-window", 128, "Maximum unacknowledged RELP messages")
+	syslogCmd.Flags().DurationVar(&syslogRELPTimeout, "relp-timeout", 10*time.Second, "Time to wait for a RELP response")
+}
+
+// syslogStreamDialer returns a dial function for the stream transports,
+// wrapping connections in TLS when useTLS is set
+func syslogStreamDialer(addr string, useTLS bool) (func() (net.Conn, error), error) {
+	if !useTLS {
+		return func() (net.Conn, error) {
+			conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+			}
+			return conn, nil
+		}, nil
+	}
+
+	serverName := syslogTLSServerName
+	if serverName == "" {
+		serverName = syslogHost
+	}
+	tlsConfig, err := buildClientTLSConfig(syslogTLSCA, syslogTLSCert, syslogTLSKey, serverName, syslogTLSInsecure)
+	if err != nil {
+		return nil, err
+	}
+	first := true
+	return func() (net.Conn, error) {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", addr, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to establish TLS with %s: %w", addr, err)
+		}
+		if first {
+			first = false
+			state := conn.ConnectionState()
+			fmt.Printf("TLS: %s, %s, server certificate CN=%s\n", tls.VersionName(state.Version),
+				tls.CipherSuiteName(state.CipherSuite), state.PeerCertificates[0].Subject.CommonName)
+		}
+		return conn, nil
+	}, nil
+}
+
+// newSyslogSender creates the sender for the configured transport
+func newSyslogSender(addr string) (syslogSender, error) {
+	switch syslogTransport {
+	case "udp":
+		conn, err := net.Dial("udp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		return &syslogUDPSender{conn: conn}, nil
+	case "tcp", "tls":
+		if syslogFraming != "octet-counting" && syslogFraming != "non-transparent" {
+			return nil, fmt.Errorf("invalid framing: %s (must be octet-counting or non-transparent)", syslogFraming)
+		}
+		trailer, err := syslogTrailerBytes(syslogTrailer)
+		if err != nil {
+			return nil, err
+		}
+		if syslogConnections < 1 {
+			return nil, fmt.Errorf("--connections must be at least 1")
+		}
+		dial, err := syslogStreamDialer(addr, syslogTransport == "tls")
+		if err != nil {
+			return nil, err
+		}
+		pool, err := newSyslogStreamPool(syslogConnections, dial)
+		if err != nil {
+			return nil, err
+		}
+		pool.framing = syslogFraming
+		pool.trailer = trailer
+		pool.maxMessages = syslogConnMaxMessages
+		pool.maxRetries = syslogMaxRetries
+		pool.reconnectInterval = syslogReconnectInterval
+		pool.writeTimeout = 10 * time.Second
+		return pool, nil
+	case "relp", "relp-tls":
+		if syslogRELPWindow < 1 {
+			return nil, fmt.Errorf("--relp-window must be at least 1")
+		}
+		dial, err := syslogStreamDialer(addr, syslogTransport == "relp-tls")
+		if err != nil {
+			return nil, err
+		}
+		sender, err := newRELPSender(syslogRELPWindow, syslogRELPTimeout, dial)
+		if err != nil {
+			return nil, err
+		}
+		sender.maxRetries = syslogMaxRetries
+		sender.reconnectInterval = syslogReconnectInterval
+		return sender, nil
+	}
+	return nil, fmt.Errorf("invalid transport: %s (must be udp, tcp, tls, relp or relp-tls)", syslogTransport)
 }
 
 func runSyslog(cmd *cobra.Command, args []string) error {
@@ -58,19 +260,53 @@ func runSyslog(cmd *cobra.Command, args []string) error {
 	}
 
 	// Validate message type
-	validTypes := map[string]bool{"generic": true, "tms": true, "firewall": true, "ids": true}
-	if !validTypes[syslogType] {
-		return fmt.Errorf("invalid message type: %s (must be generic, tms, firewall, or ids)", syslogType)
+	validType := false
+	for _, t := range generators.SyslogTypes {
+		validType = validType || t == syslogType
+	}
+	if !validType {
+		return fmt.Errorf("invalid message type: %s (must be one of %s)", syslogType, strings.Join(generators.SyslogTypes, ", "))
+	}
+
+	// RFC 5425 assigns 6514 to syslog over TLS; 2514 is the customary RELP port
+	if !cmd.Flags().Changed("port") {
+		switch syslogTransport {
+		case "tls":
+			syslogPort = 6514
+		case "relp", "relp-tls":
+			syslogPort = 2514
+		}
+	}
+
+	opts := generators.Options{RFC: syslogRFC, BOM: syslogBOM}
+	var err error
+	if opts.Facilities, err = generators.ParseWeights(syslogFacility, generators.Facilities, 23); err != nil {
+		return fmt.Errorf("invalid --facility: %w", err)
+	}
+	if opts.Severities, err = generators.ParseWeights(syslogSeverity, generators.Severities, 7); err != nil {
+		return fmt.Errorf("invalid --severity: %w", err)
+	}
+	if opts.Headers, err = generators.ParseWeights(syslogHeaders, generators.HeaderVariants, -1); err != nil {
+		return fmt.Errorf("invalid --header-variants: %w", err)
+	}
+	if opts.LEEF, err = generators.ParseLEEFFormat(syslogLEEFVersion, syslogLEEFDelimiter); err != nil {
+		return err
 	}
 
 	addr := fmt.Sprintf("%s:%d", syslogHost, syslogPort)
-	conn, err := net.Dial("udp", addr)
+	sender, err := newSyslogSender(addr)
 	if err != nil {
-		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+		return err
 	}
-	defer conn.Close()
 
-	fmt.Printf("Sending fake syslog (type=%s, RFC%s) to UDP %s at %d msg/s\n", syslogType, syslogRFC, addr, syslogRate)
+	fmt.Printf("Sending fake syslog (type=%s, RFC%s) to %s %s at %d msg/s\n",
+		syslogType, syslogRFC, strings.ToUpper(syslogTransport), addr, syslogRate)
+	switch syslogTransport {
+	case "tcp", "tls":
+		fmt.Printf("Framing: %s, connections: %d\n", syslogFraming, syslogConnections)
+	case "relp", "relp-tls":
+		fmt.Printf("RELP window: %d\n", syslogRELPWindow)
+	}
 	if syslogCount > 0 {
 		fmt.Printf("Will send %d messages total\n", syslogCount)
 	} else {
@@ -92,21 +328,19 @@ func runSyslog(cmd *cobra.Command, args []string) error {
 		select {
 		case <-sigChan:
 			fmt.Printf("\nStopped. Sent %d messages in %v\n", sent, time.Since(startTime))
+			// Closing first lets RELP collect outstanding acks for the stats
+			sender.Close()
+			sender.Print()
 			return nil
 		case <-ticker.C:
-			var msg string
-			switch syslogType {
-			case "tms":
-				msg = generators.GenerateTMSSyslog()
-			case "firewall":
-				msg = generators.GenerateFirewallSyslog()
-			case "ids":
-				msg = generators.GenerateIDSSyslog()
-			default:
-				msg = generators.GenerateSyslogMessage(syslogRFC)
+			m, err := generators.NewSyslogMessage(syslogType, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating message: %v\n", err)
+				continue
 			}
+			msg := []byte(m.Apply(opts).Render(opts))
 
-			if _, err := conn.Write([]byte(msg)); err != nil {
+			if err := sender.Send(msg); err != nil {
 				fmt.Fprintf(os.Stderr, "Error sending: %v\n", err)
 				continue
 			}
@@ -118,6 +352,8 @@ func runSyslog(cmd *cobra.Command, args []string) error {
 
 			if syslogCount > 0 && sent >= syslogCount {
 				fmt.Printf("Completed. Sent %d messages in %v\n", sent, time.Since(startTime))
+				sender.Close()
+				sender.Print()
 				return nil
 			}
 		}
@@ -0,0 +1,388 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relpMaxTxnr is the largest transaction number; numbering wraps back to 1
+const relpMaxTxnr = 999999999
+
+// relpOffers is the data of the session open command
+const relpOffers = "relp_version=0\nrelp_software=fakedata\ncommands=syslog"
+
+// relpFrame is one RELP frame: TXNR SP COMMAND SP DATALEN [SP DATA] LF
+type relpFrame struct {
+	txnr    int
+	command string
+	data    []byte
+	err     error
+	// recv is when the frame was read off the connection
+	recv time.Time
+}
+
+// appendRELPFrame encodes a frame onto buf
+func appendRELPFrame(buf []byte, txnr int, command string, data []byte) []byte {
+	buf = strconv.AppendInt(buf, int64(txnr), 10)
+	buf = append(buf, ' ')
+	buf = append(buf, command...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(len(data)), 10)
+	if len(data) > 0 {
+		buf = append(buf, ' ')
+		buf = append(buf, data...)
+	}
+	return append(buf, '\n')
+}
+
+// readRELPFrame decodes the next frame from r
+func readRELPFrame(r *bufio.Reader) (relpFrame, error) {
+	var f relpFrame
+	field, err := r.ReadString(' ')
+	if err != nil {
+		return f, err
+	}
+	if f.txnr, err = strconv.Atoi(strings.TrimSpace(field)); err != nil {
+		return f, fmt.Errorf("invalid RELP transaction number %q", field)
+	}
+	if field, err = r.ReadString(' '); err != nil {
+		return f, err
+	}
+	f.command = strings.TrimSuffix(field, " ")
+
+	// DATALEN ends in SP when data follows, or directly in the trailer
+	length := 0
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return f, err
+		}
+		if c == '\n' {
+			if length != 0 {
+				return f, fmt.Errorf("RELP frame %d declares %d bytes but has no data", f.txnr, length)
+			}
+			return f, nil
+		}
+		if c == ' ' {
+			break
+		}
+		if c < '0' || c > '9' {
+			return f, fmt.Errorf("invalid RELP data length in frame %d", f.txnr)
+		}
+		length = length*10 + int(c-'0')
+	}
+	f.data = make([]byte, length)
+	if _, err := io.ReadFull(r, f.data); err != nil {
+		return f, err
+	}
+	if c, err := r.ReadByte(); err != nil {
+		return f, err
+	} else if c != '\n' {
+		return f, fmt.Errorf("missing trailer after RELP frame %d", f.txnr)
+	}
+	return f, nil
+}
+
+// relpPending is a message waiting for its rsp
+type relpPending struct {
+	txnr int
+	msg  []byte
+	sent time.Time
+}
+
+// relpSender delivers syslog messages over a RELP session. Up to window
+// messages may be unacknowledged; when the session breaks they are
+// retransmitted with new transaction numbers on the next session.
+type relpSender struct {
+	dial              func() (net.Conn, error)
+	window            int
+	ackTimeout        time.Duration
+	maxRetries        int
+	reconnectInterval time.Duration
+
+	conn    net.Conn
+	frames  chan relpFrame
+	txnr    int
+	pending []relpPending
+	closed  bool
+
+	acked         int
+	nacked        int
+	retransmitted int
+	reconnects    int
+	dropped       int
+	unacked       int
+	latency       *latencyStats
+}
+
+// newRELPSender opens the first session so that an unreachable or refusing
+// receiver is reported immediately
+func newRELPSender(window int, ackTimeout time.Duration, dial func() (net.Conn, error)) (*relpSender, error) {
+	s := &relpSender{
+		dial:       dial,
+		window:     window,
+		ackTimeout: ackTimeout,
+		latency:    &latencyStats{},
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// nextTxnr returns the next transaction number of the session
+func (s *relpSender) nextTxnr() int {
+	s.txnr++
+	if s.txnr > relpMaxTxnr {
+		s.txnr = 1
+	}
+	return s.txnr
+}
+
+// open starts a session: it connects, exchanges offers and retransmits any
+// messages left unacknowledged by the previous session
+func (s *relpSender) open() error {
+	conn, err := s.dial()
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.frames = make(chan relpFrame, s.window+2)
+	s.txnr = 0
+	go func(r *bufio.Reader, frames chan<- relpFrame) {
+		defer close(frames)
+		for {
+			f, err := readRELPFrame(r)
+			if err != nil {
+				frames <- relpFrame{err: err}
+				return
+			}
+			f.recv = time.Now()
+			frames <- f
+		}
+	}(bufio.NewReader(conn), s.frames)
+
+	if err := s.write(appendRELPFrame(nil, s.nextTxnr(), "open", []byte(relpOffers))); err != nil {
+		return s.fail(fmt.Errorf("failed to send RELP open: %w", err))
+	}
+	rsp, err := s.wait()
+	if err != nil {
+		return s.fail(fmt.Errorf("failed to open RELP session: %w", err))
+	}
+	if rsp.command != "rsp" || rsp.txnr != 1 {
+		return s.fail(fmt.Errorf("unexpected RELP %s %d in reply to open", rsp.command, rsp.txnr))
+	}
+	status, offers, _ := strings.Cut(string(rsp.data), "\n")
+	if !strings.HasPrefix(status, "200") {
+		return s.fail(fmt.Errorf("RELP open refused: %s", status))
+	}
+	if !relpOffersCommand(offers, "syslog") {
+		return s.fail(fmt.Errorf("RELP server does not support the syslog command"))
+	}
+
+	var buf []byte
+	for i := range s.pending {
+		s.pending[i].txnr = s.nextTxnr()
+		buf = appendRELPFrame(buf, s.pending[i].txnr, "syslog", s.pending[i].msg)
+	}
+	if len(buf) > 0 {
+		if err := s.write(buf); err != nil {
+			return s.fail(fmt.Errorf("failed to retransmit: %w", err))
+		}
+		s.retransmitted += len(s.pending)
+	}
+	return nil
+}
+
+// relpOffersCommand reports whether the commands offer lists command
+func relpOffersCommand(offers, command string) bool {
+	for _, line := range strings.Split(offers, "\n") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok && name == "commands" {
+			for _, c := range strings.Split(value, ",") {
+				if c == command {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// fail drops the current connection and returns err
+func (s *relpSender) fail(err error) error {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *relpSender) write(buf []byte) error {
+	s.conn.SetWriteDeadline(time.Now().Add(s.ackTimeout))
+	_, err := s.conn.Write(buf)
+	return err
+}
+
+// wait returns the next frame from the server within the ack timeout
+func (s *relpSender) wait() (relpFrame, error) {
+	select {
+	case f, ok := <-s.frames:
+		if !ok {
+			return f, io.EOF
+		}
+		return f, f.err
+	case <-time.After(s.ackTimeout):
+		return relpFrame{}, fmt.Errorf("no RELP response within %v", s.ackTimeout)
+	}
+}
+
+// next returns the next server frame, blocking up to the ack timeout when
+// block is set. Otherwise ok is false if no frame has arrived yet.
+func (s *relpSender) next(block bool) (f relpFrame, ok bool, err error) {
+	if block {
+		f, err = s.wait()
+		return f, true, err
+	}
+	select {
+	case f, open := <-s.frames:
+		if !open {
+			return f, true, io.EOF
+		}
+		return f, true, f.err
+	default:
+		return f, false, nil
+	}
+}
+
+// handle applies a server frame to the pending window
+func (s *relpSender) handle(f relpFrame) error {
+	switch f.command {
+	case "rsp":
+		for i, p := range s.pending {
+			if p.txnr != f.txnr {
+				continue
+			}
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			if bytes.HasPrefix(f.data, []byte("200")) {
+				s.acked++
+				s.latency.record(f.recv.Sub(p.sent))
+			} else {
+				// A negative rsp is final; the message is not retried
+				s.nacked++
+				if s.nacked <= 10 {
+					fmt.Fprintf(os.Stderr, "RELP message %d rejected: %s\n", f.txnr, f.data)
+				}
+			}
+			return nil
+		}
+		return nil
+	case "serverclose":
+		return fmt.Errorf("server closed the RELP session")
+	}
+	return fmt.Errorf("unexpected RELP command %q", f.command)
+}
+
+// reconnect replaces a broken session, retrying up to maxRetries times. When
+// every attempt fails the pending messages are dropped.
+func (s *relpSender) reconnect(cause error) error {
+	s.fail(nil)
+	err := cause
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.reconnectInterval)
+		}
+		if err = s.open(); err == nil {
+			s.reconnects++
+			return nil
+		}
+	}
+	s.dropped += len(s.pending)
+	s.pending = nil
+	return err
+}
+
+func (s *relpSender) Send(msg []byte) error {
+	if s.conn == nil {
+		if err := s.reconnect(nil); err != nil {
+			s.dropped++
+			return err
+		}
+	}
+
+	// Apply acks that have already arrived, and wait while the window is full
+	for {
+		f, ok, err := s.next(len(s.pending) >= s.window)
+		if !ok {
+			break
+		}
+		if err == nil {
+			err = s.handle(f)
+		}
+		if err != nil {
+			if err := s.reconnect(err); err != nil {
+				s.dropped++
+				return err
+			}
+		}
+	}
+
+	p := relpPending{txnr: s.nextTxnr(), msg: msg, sent: time.Now()}
+	s.pending = append(s.pending, p)
+	if err := s.write(appendRELPFrame(nil, p.txnr, "syslog", msg)); err != nil {
+		// The message is pending, so a successful reconnect retransmits it
+		return s.reconnect(err)
+	}
+	return nil
+}
+
+// Close waits for outstanding acks, then closes the session with the
+// close/rsp/serverclose handshake
+func (s *relpSender) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.conn == nil {
+		s.unacked = len(s.pending)
+		return nil
+	}
+	for len(s.pending) > 0 {
+		f, err := s.wait()
+		if err == nil {
+			err = s.handle(f)
+		}
+		if err != nil {
+			break
+		}
+	}
+	s.unacked = len(s.pending)
+
+	txnr := s.nextTxnr()
+	if err := s.write(appendRELPFrame(nil, txnr, "close", nil)); err == nil {
+		for {
+			f, err := s.wait()
+			if err != nil || (f.command == "rsp" && f.txnr == txnr) {
+				break
+			}
+			s.handle(f)
+		}
+	}
+	return s.fail(nil)
+}
+
+// Print writes session statistics to stdout
+func (s *relpSender) Print() {
+	fmt.Printf("RELP acked: %d, rejected: %d, retransmitted: %d, reconnects: %d, dropped: %d, unacked at close: %d\n",
+		s.acked, s.nacked, s.retransmitted, s.reconnects, s.dropped, s.unacked)
+	s.latency.Print("Ack latency")
+}
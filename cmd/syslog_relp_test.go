@@ -0,0 +1,100 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadRELPFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		txnr    int
+		command string
+		data    string
+		wantErr bool
+	}{
+		{"rsp with data", "3 rsp 6 200 OK\n", 3, "rsp", "200 OK", false},
+		{"no data", "7 rsp 0\n", 7, "rsp", "", false},
+		{"serverclose", "0 serverclose 0\n", 0, "serverclose", "", false},
+		{"data with line breaks", "12 syslog 11 <13>a\nb\nc d\n", 12, "syslog", "<13>a\nb\nc d", false},
+		{"open offers", "1 rsp 48 200 OK\nrelp_version=0\ncommands=syslog,open,close\n", 1, "rsp", "200 OK\nrelp_version=0\ncommands=syslog,open,close", false},
+		{"length without data", "4 rsp 5\n", 0, "", "", true},
+		{"bad transaction number", "x rsp 0\n", 0, "", "", true},
+		{"bad data length", "5 rsp 1a 2\n", 0, "", "", true},
+		{"missing trailer", "6 rsp 2 OKX", 0, "", "", true},
+		{"short data", "8 rsp 10 200 OK\n", 0, "", "", true},
+		{"truncated header", "9 rsp", 0, "", "", true},
+		{"empty", "", 0, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := readRELPFrame(bufio.NewReader(strings.NewReader(tt.input)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readRELPFrame(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if f.txnr != tt.txnr || f.command != tt.command || string(f.data) != tt.data {
+				t.Errorf("readRELPFrame(%q) = %d %q %q, want %d %q %q",
+					tt.input, f.txnr, f.command, f.data, tt.txnr, tt.command, tt.data)
+			}
+		})
+	}
+}
+
+func TestRELPFrameRoundTrip(t *testing.T) {
+	frames := []relpFrame{
+		{txnr: 1, command: "open", data: []byte(relpOffers)},
+		{txnr: 2, command: "syslog", data: []byte("<34>Oct 11 22:14:15 host app: line one\n  line two")},
+		{txnr: 3, command: "close"},
+		{txnr: 999999999, command: "rsp", data: []byte("200 OK")},
+	}
+	var buf []byte
+	for _, f := range frames {
+		buf = appendRELPFrame(buf, f.txnr, f.command, f.data)
+	}
+
+	r := bufio.NewReader(bytes.NewReader(buf))
+	for _, want := range frames {
+		got, err := readRELPFrame(r)
+		if err != nil {
+			t.Fatalf("reading frame %d: %v", want.txnr, err)
+		}
+		if got.txnr != want.txnr || got.command != want.command || !bytes.Equal(got.data, want.data) {
+			t.Errorf("frame = %d %q %q, want %d %q %q", got.txnr, got.command, got.data, want.txnr, want.command, want.data)
+		}
+	}
+	if _, err := readRELPFrame(r); err != io.EOF {
+		t.Errorf("after the last frame error = %v, want EOF", err)
+	}
+}
+
+func TestRELPSenderHandle(t *testing.T) {
+	s := &relpSender{
+		latency: &latencyStats{},
+		pending: []relpPending{{txnr: 2}, {txnr: 3}, {txnr: 4}},
+	}
+	for _, f := range []relpFrame{
+		{txnr: 3, command: "rsp", data: []byte("200 OK")},
+		{txnr: 2, command: "rsp", data: []byte("500 rejected")},
+		{txnr: 9, command: "rsp", data: []byte("200 OK")},
+	} {
+		if err := s.handle(f); err != nil {
+			t.Fatalf("handle(%d rsp) = %v", f.txnr, err)
+		}
+	}
+	if s.acked != 1 || s.nacked != 1 || len(s.pending) != 1 || s.pending[0].txnr != 4 {
+		t.Errorf("acked %d, nacked %d, pending %+v; want 1, 1, [4]", s.acked, s.nacked, s.pending)
+	}
+	if err := s.handle(relpFrame{command: "serverclose"}); err == nil {
+		t.Error("handle(serverclose) = nil, want an error")
+	}
+}
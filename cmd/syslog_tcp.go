@@ -0,0 +1,165 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// syslogSender delivers syslog messages over one transport
+type syslogSender interface {
+	Send(msg []byte) error
+	Close() error
+	Print()
+}
+
+// syslogUDPSender writes one datagram per message
+type syslogUDPSender struct {
+	conn net.Conn
+}
+
+func (s *syslogUDPSender) Send(msg []byte) error {
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+func (s *syslogUDPSender) Close() error {
+	return s.conn.Close()
+}
+
+func (s *syslogUDPSender) Print() {}
+
+// syslogFrame applies RFC 6587 framing: octet-counting prefixes the message
+// length, non-transparent framing appends the trailer
+func syslogFrame(msg []byte, framing string, trailer []byte) []byte {
+	if framing == "octet-counting" {
+		frame := make([]byte, 0, len(msg)+8)
+		frame = strconv.AppendInt(frame, int64(len(msg)), 10)
+		frame = append(frame, ' ')
+		return append(frame, msg...)
+	}
+	frame := make([]byte, 0, len(msg)+len(trailer))
+	frame = append(frame, msg...)
+	return append(frame, trailer...)
+}
+
+// syslogTrailerBytes returns the non-transparent framing trailer for a name
+func syslogTrailerBytes(name string) ([]byte, error) {
+	switch name {
+	case "lf":
+		return []byte{'\n'}, nil
+	case "crlf":
+		return []byte{'\r', '\n'}, nil
+	case "nul":
+		return []byte{0}, nil
+	}
+	return nil, fmt.Errorf("invalid trailer: %s (must be lf, crlf or nul)", name)
+}
+
+// syslogStreamPool spreads framed messages round-robin over a pool of stream
+// connections, reconnecting broken connections and optionally rotating each
+// connection after a number of messages
+type syslogStreamPool struct {
+	dial              func() (net.Conn, error)
+	framing           string
+	trailer           []byte
+	maxMessages       int
+	maxRetries        int
+	reconnectInterval time.Duration
+	writeTimeout      time.Duration
+
+	conns      []net.Conn
+	counts     []int
+	used       []bool
+	next       int
+	reconnects int
+	rotations  int
+	dropped    int
+}
+
+// newSyslogStreamPool opens size connections up front so that an unreachable
+// receiver is reported immediately
+func newSyslogStreamPool(size int, dial func() (net.Conn, error)) (*syslogStreamPool, error) {
+	p := &syslogStreamPool{
+		dial:   dial,
+		conns:  make([]net.Conn, size),
+		counts: make([]int, size),
+		used:   make([]bool, size),
+	}
+	for i := range p.conns {
+		conn, err := dial()
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.conns[i] = conn
+		p.used[i] = true
+	}
+	return p, nil
+}
+
+func (p *syslogStreamPool) Send(msg []byte) error {
+	i := p.next
+	p.next = (p.next + 1) % len(p.conns)
+	frame := syslogFrame(msg, p.framing, p.trailer)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if p.conns[i] == nil {
+			var conn net.Conn
+			if conn, err = p.dial(); err == nil {
+				if p.used[i] {
+					p.reconnects++
+				}
+				p.conns[i] = conn
+				p.used[i] = true
+				p.counts[i] = 0
+			}
+		}
+		if p.conns[i] != nil {
+			if p.writeTimeout > 0 {
+				p.conns[i].SetWriteDeadline(time.Now().Add(p.writeTimeout))
+			}
+			if _, err = p.conns[i].Write(frame); err == nil {
+				break
+			}
+			p.conns[i].Close()
+			p.conns[i] = nil
+		}
+		if attempt >= p.maxRetries {
+			p.dropped++
+			return err
+		}
+		time.Sleep(p.reconnectInterval)
+	}
+
+	p.counts[i]++
+	if p.maxMessages > 0 && p.counts[i] >= p.maxMessages {
+		// Rotation is a planned reconnect, not a failure
+		p.conns[i].Close()
+		p.conns[i] = nil
+		p.used[i] = false
+		p.rotations++
+	}
+	return nil
+}
+
+func (p *syslogStreamPool) Close() error {
+	for i, conn := range p.conns {
+		if conn != nil {
+			conn.Close()
+			p.conns[i] = nil
+		}
+	}
+	return nil
+}
+
+// Print writes connection statistics to stdout
+func (p *syslogStreamPool) Print() {
+	fmt.Printf("Connections: %d, reconnects: %d, rotations: %d, dropped: %d\n",
+		len(p.conns), p.reconnects, p.rotations, p.dropped)
+}
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,19 +20,28 @@ var tcpHost string
 var tcpPort int
 var tcpRate int
 var tcpCount int
+var tcpType string
+var tcpRFC string
+var tcpLEEFVersion string
+var tcpLEEFDelimiter string
 
 var tcpCmd = &cobra.Command{
 	Use:   "tcp",
-	Short: "Send fake JSON data over TCP",
-	Long: `Send fake JSON data over TCP to test TCP ingestion.
+	Short: "Send fake events over TCP",
+	Long: `Send fake events over TCP to test TCP ingestion, one event per line.
 
-Each message is a JSON object with fields like:
+By default each message is a JSON object with fields like:
   - timestamp, source_ip, dest_ip, source_port, dest_port
   - username, action, status, process
   - bytes_sent, bytes_recv, duration_ms, session_id
 
+The multiline types (stack traces, pretty JSON, indented logs) span several
+lines per event, for testing multiline aggregation in collectors.
+
 Example:
   fakedata tcp --host 127.0.0.1 --port 5001 --rate 100
+  fakedata tcp --host 127.0.0.1 --port 5001 --type multiline-java --rate 10
+  fakedata tcp --host 127.0.0.1 --port 5001 --type multiline --rate 10
 `,
 	RunE: runTCP,
 }
@@ -41,9 +51,21 @@ func init() {
 	tcpCmd.Flags().IntVar(&tcpPort, "port", 5001, "Target port")
 	tcpCmd.Flags().IntVar(&tcpRate, "rate", 10, "Messages per second")
 	tcpCmd.Flags().IntVar(&tcpCount, "count", 0, "Total messages to send (0 = unlimited)")
+	tcpCmd.Flags().StringVar(&tcpType, "type", "json", "Event type: "+strings.Join(generators.EventTypes, ", "))
+	tcpCmd.Flags().StringVar(&tcpRFC, "rfc", "3164", "Syslog RFC format for syslog-style types (3164 or 5424)")
+	tcpCmd.Flags().StringVar(&tcpLEEFVersion, "leef-version", "2.0", "LEEF version for leef-* types (1.0 or 2.0)")
+	tcpCmd.Flags().StringVar(&tcpLEEFDelimiter, "leef-delimiter", "tab", "LEEF 2.0 attribute delimiter: a character, tab, or hex code like x5E")
 }
 
 func runTCP(cmd *cobra.Command, args []string) error {
+	if !generators.ValidEventType(tcpType) {
+		return fmt.Errorf("invalid event type: %s (must be one of %s)", tcpType, strings.Join(generators.EventTypes, ", "))
+	}
+	leef, err := generators.ParseLEEFFormat(tcpLEEFVersion, tcpLEEFDelimiter)
+	if err != nil {
+		return err
+	}
+
 	addr := fmt.Sprintf("%s:%d", tcpHost, tcpPort)
 	conn, err := net.Dial("tcp", addr)
 	if err != nil {
@@ -51,7 +73,7 @@ func runTCP(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 
-	fmt.Printf("Sending fake JSON data to TCP %s at %d msg/s\n", addr, tcpRate)
+	fmt.Printf("Sending fake %s events to TCP %s at %d msg/s\n", tcpType, addr, tcpRate)
 	if tcpCount > 0 {
 		fmt.Printf("Will send %d messages total\n", tcpCount)
 	} else {
@@ -68,6 +90,7 @@ func runTCP(cmd *cobra.Command, args []string) error {
 
 	sent := 0
 	startTime := time.Now()
+	opts := generators.Options{RFC: tcpRFC, LEEF: leef}
 
 	for {
 		select {
@@ -75,7 +98,7 @@ func runTCP(cmd *cobra.Command, args []string) error {
 			fmt.Printf("\nStopped. Sent %d messages in %v\n", sent, time.Since(startTime))
 			return nil
 		case <-ticker.C:
-			data, err := generators.GenerateJSONEvent()
+			data, err := generators.GenerateEvent(tcpType, opts)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error generating event: %v\n", err)
 				continue
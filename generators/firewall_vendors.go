@@ -0,0 +1,607 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FirewallVendors lists the vendor firewall dialects, each also an event type
+var FirewallVendors = []string{"asa", "panos", "fortigate", "checkpoint", "srx"}
+
+// servicePorts names the services behind AttackPorts
+var servicePorts = map[int]string{
+	22: "ssh", 23: "telnet", 25: "smtp", 53: "dns", 80: "http", 123: "ntp",
+	161: "snmp", 443: "https", 445: "smb", 1433: "ms-sql", 1900: "ssdp",
+	3306: "mysql", 3389: "rdp", 5060: "sip", 5900: "vnc", 6379: "redis",
+	8080: "http-alt", 11211: "memcached", 27017: "mongodb",
+}
+
+// udpPorts are the AttackPorts whose services run over UDP
+var udpPorts = map[int]bool{53: true, 123: true, 161: true, 1900: true, 5060: true, 11211: true}
+
+// fwFlow is an inbound connection from a known-bad address to a published
+// server, as seen by a perimeter firewall doing destination NAT
+type fwFlow struct {
+	src     string
+	sport   int
+	dst     string // public address the attacker connects to
+	dport   int
+	natDst  string // internal server behind dst
+	proto   string // "tcp" or "udp"
+	service string
+	bytesTx int // client to server
+	bytesRx int // server to client
+	pktsTx  int
+	pktsRx  int
+	elapsed int // seconds
+	session int
+}
+
+// newFWFlow picks a flow from the shared MaliciousIPs and AttackPorts pools
+func newFWFlow() fwFlow {
+	f := fwFlow{
+		src:     MaliciousIPs[rand.Intn(len(MaliciousIPs))],
+		sport:   rand.Intn(65535-1024) + 1024,
+		dst:     fmt.Sprintf("203.0.113.%d", rand.Intn(254)+1),
+		dport:   AttackPorts[rand.Intn(len(AttackPorts))],
+		natDst:  fmt.Sprintf("10.10.%d.%d", rand.Intn(4), rand.Intn(254)+1),
+		proto:   "tcp",
+		pktsTx:  rand.Intn(40) + 1,
+		pktsRx:  rand.Intn(40),
+		elapsed: rand.Intn(300),
+		session: rand.Intn(9000000) + 100000,
+	}
+	if udpPorts[f.dport] {
+		f.proto = "udp"
+	}
+	f.service = servicePorts[f.dport]
+	f.bytesTx = f.pktsTx * (rand.Intn(1400) + 60)
+	f.bytesRx = f.pktsRx * (rand.Intn(1400) + 60)
+	return f
+}
+
+// protoNumber returns the IP protocol number of a lowercase protocol name
+func (f fwFlow) protoNumber() int {
+	if f.proto == "udp" {
+		return 17
+	}
+	return 6
+}
+
+// flowSD returns the fakedata structured data element for vendors that do
+// not send structured data of their own
+func (f fwFlow) flowSD(vendor, action string) SDElement {
+	return SDElement{ID: sdID(vendor), Params: []SDParam{
+		{"action", action},
+		{"proto", strings.ToUpper(f.proto)},
+		{"src", f.src},
+		{"spt", strconv.Itoa(f.sport)},
+		{"dst", f.dst},
+		{"dpt", strconv.Itoa(f.dport)},
+	}}
+}
+
+// NewASAEvent generates a Cisco ASA event. ASAs log to local4 with the
+// message severity and ID in the %ASA-level-id tag, no program name, and the
+// year in the timestamp:
+// <164>Oct 18 2026 21:48:30 asa-01 : %ASA-4-106023: Deny tcp src outside:...
+func NewASAEvent() *SyslogMessage {
+	f := newFWFlow()
+	n := rand.Intn(8) + 1
+	proto := strings.ToUpper(f.proto)
+
+	var level int
+	var id, action, text string
+	switch rand.Intn(6) {
+	case 0:
+		level, id, action = 4, "106023", "deny"
+		text = fmt.Sprintf(`Deny %s src outside:%s/%d dst inside:%s/%d by access-group "outside_access_in" [0x%08x, 0x0]`,
+			f.proto, f.src, f.sport, f.natDst, f.dport, rand.Uint32())
+	case 1:
+		level, action = 2, "deny"
+		if f.proto == "tcp" {
+			id = "106001"
+			text = fmt.Sprintf("Inbound TCP connection denied from %s/%d to %s/%d flags SYN  on interface outside",
+				f.src, f.sport, f.natDst, f.dport)
+		} else {
+			id = "106006"
+			text = fmt.Sprintf("Deny inbound UDP from %s/%d to %s/%d on interface outside",
+				f.src, f.sport, f.natDst, f.dport)
+		}
+	case 2:
+		level, id, action = 6, "106100", "deny"
+		text = fmt.Sprintf("access-list outside_access_in denied %s outside/%s(%d) -> inside/%s(%d) hit-cnt %d first hit [0x%08x, 0x0]",
+			f.proto, f.src, f.sport, f.natDst, f.dport, rand.Intn(50)+1, rand.Uint32())
+	case 3:
+		level, id, action = 6, "302013", "built"
+		if f.proto == "udp" {
+			id = "302015"
+		}
+		text = fmt.Sprintf("Built inbound %s connection %d for outside:%s/%d (%s/%d) to inside:%s/%d (%s/%d)",
+			proto, f.session, f.src, f.sport, f.src, f.sport, f.natDst, f.dport, f.dst, f.dport)
+	case 4:
+		level, id, action = 6, "302014", "teardown"
+		text = fmt.Sprintf("Teardown TCP connection %d for outside:%s/%d to inside:%s/%d duration %d:%02d:%02d bytes %d %s",
+			f.session, f.src, f.sport, f.natDst, f.dport, f.elapsed/3600, f.elapsed/60%60, f.elapsed%60,
+			f.bytesTx+f.bytesRx, []string{"TCP FINs", "TCP Reset-O", "TCP Reset-I", "SYN Timeout"}[rand.Intn(4)])
+		if f.proto == "udp" {
+			id = "302016"
+			text = fmt.Sprintf("Teardown UDP connection %d for outside:%s/%d to inside:%s/%d duration %d:%02d:%02d bytes %d",
+				f.session, f.src, f.sport, f.natDst, f.dport, f.elapsed/3600, f.elapsed/60%60, f.elapsed%60, f.bytesTx+f.bytesRx)
+		}
+	default:
+		level, id, action = 3, "710003", "deny"
+		text = fmt.Sprintf("%s access denied by ACL from %s/%d to outside:%s/%d", proto, f.src, f.sport, f.dst, f.dport)
+	}
+	msg := fmt.Sprintf("%%ASA-%d-%s: %s", level, id, text)
+
+	return &SyslogMessage{
+		Time:     time.Now(),
+		Priority: Facilities["local4"]*8 + level,
+		Hostname: fmt.Sprintf("asa-%02d", n),
+		MsgID:    id,
+		StructuredData: []SDElement{
+			f.flowSD("asa", action),
+			originSD(fmt.Sprintf("10.252.0.%d", n), "asa"),
+			metaSD(),
+		},
+		Msg:    msg,
+		BSDMsg: ": " + msg,
+		Header: HeaderYear,
+	}
+}
+
+// panThreats are PAN-OS threat names with their IDs, log subtype and severity
+var panThreats = []struct {
+	name     string
+	subtype  string
+	severity string
+}{
+	{"SSH Brute Force Attack(40015)", "vulnerability", "critical"},
+	{"Microsoft RDP Brute Force Attempt(40021)", "vulnerability", "high"},
+	{"MySQL Authentication Brute Force Attempt(40004)", "vulnerability", "high"},
+	{"Apache Log4j Remote Code Execution Vulnerability(91991)", "vulnerability", "critical"},
+	{"SIPVicious Scanner Detection(54624)", "vulnerability", "medium"},
+	{"DNS ANY Queries Brute Force DOS Attack(40033)", "vulnerability", "medium"},
+	{"TCP Port Scan(8001)", "scan", "medium"},
+	{"Cobalt Strike Beacon Command and Control Traffic Detection(86445)", "spyware", "critical"},
+	{"HTTP OPTIONS Method(30520)", "vulnerability", "informational"},
+}
+
+// panSeverities maps PAN-OS threat severities to syslog severities
+var panSeverities = map[string]int{"informational": 6, "low": 5, "medium": 4, "high": 3, "critical": 2}
+
+// panApps maps destination services to App-ID names
+var panApps = map[string]string{
+	"ssh": "ssh", "telnet": "telnet", "smtp": "smtp", "dns": "dns", "http": "web-browsing",
+	"ntp": "ntp", "snmp": "snmp", "https": "ssl", "smb": "ms-ds-smb", "ms-sql": "mssql-db",
+	"ssdp": "ssdp", "mysql": "mysql", "rdp": "ms-rdp", "sip": "sip", "vnc": "vnc",
+	"redis": "redis", "http-alt": "web-browsing", "memcached": "memcached", "mongodb": "mongodb",
+}
+
+// csvJoin joins fields as one CSV record, quoting fields that need it
+func csvJoin(fields []string) string {
+	for i, f := range fields {
+		if strings.ContainsAny(f, ",\"\n") {
+			fields[i] = `"` + strings.ReplaceAll(f, `"`, `""`) + `"`
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// NewPANOSEvent generates a Palo Alto Networks PAN-OS TRAFFIC or THREAT log
+// in the default CSV syslog format, which follows the hostname directly:
+// <14>Oct 18 21:48:30 PA-3220-01 1,2026/10/18 21:48:30,013201001234,TRAFFIC,drop,...
+func NewPANOSEvent() *SyslogMessage {
+	f := newFWFlow()
+	n := rand.Intn(4) + 1
+	now := time.Now()
+	ts := now.Format("2006/01/02 15:04:05")
+	device := fmt.Sprintf("PA-3220-%02d", n)
+	serial := fmt.Sprintf("0132010%05d", 1000+n)
+	app := panApps[f.service]
+
+	// Fields 1-31 are shared by TRAFFIC and THREAT logs
+	common := func(logType, subtype, rule, app, action, flags string) []string {
+		return []string{
+			"1", ts, serial, logType, subtype, "2561", ts,
+			f.src, f.dst, "0.0.0.0", f.natDst, rule, "", "", app, "vsys1",
+			"untrust", "dmz", "ethernet1/1", "ethernet1/2", "syslog-fwd", ts,
+			strconv.Itoa(f.session), "1", strconv.Itoa(f.sport), strconv.Itoa(f.dport),
+			"0", strconv.Itoa(f.dport), flags, f.proto, action,
+		}
+	}
+
+	var fields []string
+	var action string
+	priority := 14
+	if rand.Intn(3) == 0 {
+		t := panThreats[rand.Intn(len(panThreats))]
+		action = []string{"alert", "drop", "reset-both", "reset-server"}[rand.Intn(4)]
+		priority = Facilities["user"]*8 + panSeverities[t.severity]
+		fields = append(common("THREAT", t.subtype, "allow-dmz-"+f.service, app, action, "0x80002000"),
+			"", t.name, "any", t.severity, "client-to-server",
+			strconv.FormatInt(now.UnixNano()/1000%1e9, 10), "0x2000000000000000",
+			"Reserved", "United States", "0", "", "0",
+			// file digest, cloud, URL index, user agent, file type,
+			// X-Forwarded-For, referer, sender, subject, recipient, report ID
+			"", "", "", "", "", "", "", "", "", "", "",
+			"0", "0", "0", "0", "", device)
+	} else {
+		subtype, end := "end", []string{"tcp-fin", "aged-out", "tcp-rst-from-client", "threat"}[rand.Intn(4)]
+		action = "allow"
+		if rand.Intn(2) == 0 {
+			subtype, end = []string{"drop", "deny"}[rand.Intn(2)], "policy-deny"
+			action, app = subtype, "not-applicable"
+			f.bytesTx, f.pktsTx = rand.Intn(100)+60, 1
+			f.bytesRx, f.pktsRx, f.elapsed = 0, 0, 0
+		}
+		fields = append(common("TRAFFIC", subtype, "allow-dmz-"+f.service, app, action, "0x400000"),
+			strconv.Itoa(f.bytesTx+f.bytesRx), strconv.Itoa(f.bytesTx), strconv.Itoa(f.bytesRx),
+			strconv.Itoa(f.pktsTx+f.pktsRx), now.Add(-time.Duration(f.elapsed)*time.Second).Format("2006/01/02 15:04:05"),
+			strconv.Itoa(f.elapsed), "any", "0", strconv.FormatInt(now.UnixNano()/1000%1e9, 10), "0x0",
+			"Reserved", "United States", "0", strconv.Itoa(f.pktsTx), strconv.Itoa(f.pktsRx), end,
+			"0", "0", "0", "0", "", device, "from-policy")
+		if end == "policy-deny" {
+			fields[11] = "interzone-default"
+		}
+	}
+
+	return &SyslogMessage{
+		Time:     now,
+		Priority: priority,
+		Hostname: device,
+		MsgID:    fields[3],
+		StructuredData: []SDElement{
+			f.flowSD("panos", action),
+			originSD(fmt.Sprintf("10.251.0.%d", n), "pan-os"),
+			metaSD(),
+		},
+		Msg: csvJoin(fields),
+	}
+}
+
+// fortiIPS are FortiGate IPS signatures with their attack IDs
+var fortiIPS = []struct {
+	name string
+	id   int
+}{
+	{"SSH.Connection.Brute.Force", 15620},
+	{"MS.RDP.Connection.Brute.Force", 43853},
+	{"MySQL.Login.Brute.Force", 16038},
+	{"Apache.Log4j.Error.Log.Remote.Code.Execution", 51006},
+	{"SIPVicious.Scanner", 12698},
+	{"NTP.Monlist.Command.DoS", 37508},
+	{"Memcached.UDP.Stats.DoS", 45451},
+	{"Redis.Unauthorized.Access", 48283},
+}
+
+// fortiBare are the FortiOS log fields written without quotes; FortiOS
+// quotes every other value, even numeric ones such as logid
+var fortiBare = map[string]bool{
+	"date": true, "time": true, "eventtime": true, "srcip": true, "srcport": true,
+	"dstip": true, "dstport": true, "sessionid": true, "proto": true, "policyid": true,
+	"duration": true, "sentbyte": true, "rcvdbyte": true, "sentpkt": true, "rcvdpkt": true,
+	"crscore": true, "craction": true, "attackid": true, "incidentserialno": true,
+	"tranip": true, "tranport": true,
+}
+
+// fortiPairs renders FortiOS key=value pairs
+func fortiPairs(pairs []SDParam) string {
+	var b strings.Builder
+	for i, p := range pairs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(p.Name)
+		b.WriteByte('=')
+		if fortiBare[p.Name] {
+			b.WriteString(p.Value)
+		} else {
+			b.WriteString(strconv.Quote(p.Value))
+		}
+	}
+	return b.String()
+}
+
+// NewFortiGateEvent generates a FortiGate traffic or IPS log in FortiOS's
+// default key=value format, which has no syslog timestamp or hostname:
+// <189>date=2026-10-18 time=21:48:30 devname="FGT100F-01" ... action="deny"
+func NewFortiGateEvent() *SyslogMessage {
+	f := newFWFlow()
+	n := rand.Intn(6) + 1
+	now := time.Now().UTC()
+	device := fmt.Sprintf("FGT100F-%02d", n)
+	service := strings.ToUpper(f.service)
+
+	pairs := []SDParam{
+		{"date", now.Format("2006-01-02")},
+		{"time", now.Format("15:04:05")},
+		{"devname", device},
+		{"devid", fmt.Sprintf("FG100FTK2200%04d", n)},
+		{"eventtime", strconv.FormatInt(now.UnixNano(), 10)},
+		{"tz", "+0000"},
+	}
+	var action string
+	severity := 5 // notice
+	if rand.Intn(3) == 0 {
+		sig := fortiIPS[rand.Intn(len(fortiIPS))]
+		level := []string{"medium", "high", "critical"}[rand.Intn(3)]
+		action = []string{"dropped", "detected", "reset"}[rand.Intn(3)]
+		severity = 1 // alert
+		pairs = append(pairs, []SDParam{
+			{"logid", "0419016384"}, {"type", "utm"}, {"subtype", "ips"}, {"eventtype", "signature"},
+			{"level", "alert"}, {"vd", "root"}, {"severity", level},
+			{"srcip", f.src}, {"srccountry", "Reserved"}, {"dstip", f.natDst},
+			{"srcintf", "wan1"}, {"srcintfrole", "wan"}, {"dstintf", "dmz"}, {"dstintfrole", "dmz"},
+			{"sessionid", strconv.Itoa(f.session)}, {"action", action},
+			{"proto", strconv.Itoa(f.protoNumber())}, {"service", service}, {"policyid", "3"},
+			{"attack", sig.name}, {"srcport", strconv.Itoa(f.sport)}, {"dstport", strconv.Itoa(f.dport)},
+			{"direction", "incoming"}, {"attackid", strconv.Itoa(sig.id)}, {"profile", "default"},
+			{"ref", fmt.Sprintf("http://www.fortinet.com/ids/VID%d", sig.id)},
+			{"incidentserialno", strconv.Itoa(rand.Intn(900000000) + 100000000)},
+			{"msg", fmt.Sprintf("%s: %s,", strings.ToLower(strings.SplitN(sig.name, ".", 2)[0]), sig.name)},
+		}...)
+	} else {
+		action = "deny"
+		policy := "0"
+		if rand.Intn(2) == 0 {
+			action, policy = []string{"accept", "close", "timeout"}[rand.Intn(3)], "3"
+		} else {
+			f.bytesTx, f.bytesRx, f.pktsTx, f.pktsRx, f.elapsed = 0, 0, 0, 0, 0
+		}
+		pairs = append(pairs, []SDParam{
+			{"logid", "0000000013"}, {"type", "traffic"}, {"subtype", "forward"},
+			{"level", "notice"}, {"vd", "root"},
+			{"srcip", f.src}, {"srcport", strconv.Itoa(f.sport)}, {"srcintf", "wan1"}, {"srcintfrole", "wan"},
+			{"dstip", f.dst}, {"dstport", strconv.Itoa(f.dport)}, {"dstintf", "dmz"}, {"dstintfrole", "dmz"},
+			{"srccountry", "Reserved"}, {"dstcountry", "United States"},
+			{"sessionid", strconv.Itoa(f.session)}, {"proto", strconv.Itoa(f.protoNumber())},
+			{"action", action}, {"policyid", policy}, {"policytype", "policy"}, {"service", service},
+		}...)
+		if action == "deny" {
+			pairs = append(pairs, []SDParam{
+				{"trandisp", "noop"}, {"duration", "0"}, {"sentbyte", "0"}, {"rcvdbyte", "0"},
+				{"sentpkt", "0"}, {"rcvdpkt", "0"}, {"appcat", "unscanned"},
+				{"crscore", "30"}, {"craction", "131072"}, {"crlevel", "high"},
+			}...)
+		} else {
+			pairs = append(pairs, []SDParam{
+				{"trandisp", "dnat"}, {"tranip", f.natDst}, {"tranport", strconv.Itoa(f.dport)},
+				{"duration", strconv.Itoa(f.elapsed)},
+				{"sentbyte", strconv.Itoa(f.bytesTx)}, {"rcvdbyte", strconv.Itoa(f.bytesRx)},
+				{"sentpkt", strconv.Itoa(f.pktsTx)}, {"rcvdpkt", strconv.Itoa(f.pktsRx)},
+				{"appcat", "unscanned"},
+			}...)
+		}
+	}
+
+	return &SyslogMessage{
+		Time:     now,
+		Priority: Facilities["local7"]*8 + severity,
+		Hostname: device,
+		StructuredData: []SDElement{
+			f.flowSD("fgt", action),
+			originSD(fmt.Sprintf("10.250.0.%d", n), "fortios"),
+			metaSD(),
+		},
+		Msg:  fortiPairs(pairs),
+		Bare: true,
+	}
+}
+
+// CheckPointPEN is Check Point's private enterprise number, used in the
+// sc@2620 structured data of Log Exporter
+const CheckPointPEN = 2620
+
+// NewCheckPointEvent generates a Check Point firewall log. In RFC 5424 it
+// follows the Log Exporter syslog format, with every field in an sc@2620
+// element and no MSG; in RFC 3164 it carries the pipe-separated LEA fields
+// that OPSEC LEA clients such as fw1-loggrabber forward.
+func NewCheckPointEvent() *SyslogMessage {
+	f := newFWFlow()
+	n := rand.Intn(4) + 1
+	now := time.Now()
+	gateway := fmt.Sprintf("cp-gw%02d", n)
+	origin := fmt.Sprintf("10.249.0.%d", n)
+
+	rules := []struct {
+		number int
+		name   string
+		action string
+	}{
+		{1, "Stealth", "Drop"},
+		{2, "Block known bad", "Drop"},
+		{5, "Allow DMZ services", "Accept"},
+		{8, "Reject management", "Reject"},
+		{12, "Cleanup rule", "Drop"},
+	}
+	rule := rules[rand.Intn(len(rules))]
+	ruleUID := fmt.Sprintf("{%08X-%04X-%04X-%04X-%012X}", rand.Uint32(), rand.Intn(0x10000), rand.Intn(0x10000), rand.Intn(0x10000), rand.Int63n(1<<48))
+	loguid := fmt.Sprintf("{0x%x,0x%x,0x%x,0x%x}", now.Unix(), rand.Intn(0x100), rand.Uint32(), rand.Uint32())
+
+	params := []SDParam{
+		{"action", rule.action},
+		{"flags", strconv.Itoa(rand.Intn(8000000) + 400000)},
+		{"ifdir", "inbound"},
+		{"ifname", "eth1"},
+		{"logid", "0"},
+		{"loguid", loguid},
+		{"origin", origin},
+		{"originsicname", fmt.Sprintf("CN=%s,O=cp-mgmt.example.com.8r2kxq", gateway)},
+		{"sequencenum", strconv.Itoa(rand.Intn(1000) + 1)},
+		{"time", strconv.FormatInt(now.Unix(), 10)},
+		{"version", "5"},
+		{"dst", f.dst},
+		{"inzone", "External"},
+		{"layer_name", "Network"},
+		{"match_id", strconv.Itoa(rule.number)},
+		{"parent_rule", "0"},
+		{"rule_action", rule.action},
+		{"rule_name", rule.name},
+		{"rule_uid", ruleUID},
+		{"outzone", "DMZ"},
+		{"product", "VPN-1 & FireWall-1"},
+		{"proto", strconv.Itoa(f.protoNumber())},
+		{"s_port", strconv.Itoa(f.sport)},
+		{"service", strconv.Itoa(f.dport)},
+		{"service_id", f.service},
+		{"src", f.src},
+	}
+	if rule.action == "Accept" {
+		params = append(params, SDParam{"xlatedst", f.natDst}, SDParam{"xlatedport", strconv.Itoa(f.dport)})
+	}
+
+	lea := []string{
+		"loc=" + strconv.Itoa(rand.Intn(100000)),
+		"time=" + now.Format("2006-01-02 15:04:05"),
+		"action=" + strings.ToLower(rule.action),
+		"orig=" + origin,
+		"i/f_dir=inbound",
+		"i/f_name=eth1",
+		"has_accounting=0",
+		"uuid=<" + strings.Trim(loguid, "{}") + ">",
+		"product=VPN-1 & FireWall-1",
+		"rule=" + strconv.Itoa(rule.number),
+		"rule_uid=" + ruleUID,
+		"rule_name=" + rule.name,
+		"src=" + f.src,
+		"s_port=" + strconv.Itoa(f.sport),
+		"dst=" + f.dst,
+		"service=" + strconv.Itoa(f.dport),
+		"service_id=" + f.service,
+		"proto=" + f.proto,
+	}
+	if rule.action == "Accept" {
+		lea = append(lea, "xlatedst="+f.natDst, "xlatedport="+strconv.Itoa(f.dport))
+	}
+
+	return &SyslogMessage{
+		Time:           now,
+		Priority:       Facilities["local0"]*8 + 6,
+		Hostname:       gateway,
+		AppName:        "CheckPoint",
+		ProcID:         strconv.Itoa(rand.Intn(30000) + 1000),
+		StructuredData: []SDElement{{ID: fmt.Sprintf("sc@%d", CheckPointPEN), Params: params}},
+		BSDMsg:         strings.Join(lea, "|"),
+	}
+}
+
+// JuniperSRXSDID is the structured data ID of Junos RT_FLOW logs from SRX
+// firewalls (enterprise 2636 followed by the platform OID)
+const JuniperSRXSDID = "junos@2636.1.1.1.2.129"
+
+// NewSRXEvent generates a Juniper SRX RT_FLOW session log. In RFC 5424 this
+// is Junos structured-data mode, with the event name as MSGID and the fields
+// under junos@2636; in RFC 3164 the event name prefixes the message:
+// <14>Oct 18 21:48:30 srx-01 RT_FLOW: RT_FLOW_SESSION_DENY: session denied ...
+func NewSRXEvent() *SyslogMessage {
+	f := newFWFlow()
+	n := rand.Intn(6) + 1
+	proto := strconv.Itoa(f.protoNumber())
+	service := "junos-" + f.service
+	policy := "dmz-" + f.service
+	conn := fmt.Sprintf("%s/%d->%s/%d", f.src, f.sport, f.dst, f.dport)
+	natConn := fmt.Sprintf("%s/%d->%s/%d", f.src, f.sport, f.natDst, f.dport)
+	iface := "ge-0/0/0.0"
+
+	params := []SDParam{
+		{"source-address", f.src},
+		{"source-port", strconv.Itoa(f.sport)},
+		{"destination-address", f.dst},
+		{"destination-port", strconv.Itoa(f.dport)},
+		{"connection-tag", "0"},
+		{"service-name", service},
+	}
+	var event, msg string
+	switch rand.Intn(3) {
+	case 0:
+		event = "RT_FLOW_SESSION_CREATE"
+		params = append(params, []SDParam{
+			{"nat-source-address", f.src}, {"nat-source-port", strconv.Itoa(f.sport)},
+			{"nat-destination-address", f.natDst}, {"nat-destination-port", strconv.Itoa(f.dport)},
+			{"nat-connection-tag", "0"},
+			{"src-nat-rule-type", "N/A"}, {"src-nat-rule-name", "N/A"},
+			{"dst-nat-rule-type", "destination rule"}, {"dst-nat-rule-name", "dnat-" + f.service},
+			{"protocol-id", proto}, {"policy-name", policy},
+			{"source-zone-name", "untrust"}, {"destination-zone-name", "dmz"},
+			{"session-id-32", strconv.Itoa(f.session)},
+			{"username", "N/A"}, {"roles", "N/A"}, {"packet-incoming-interface", iface},
+			{"application", "UNKNOWN"}, {"nested-application", "UNKNOWN"}, {"encrypted", "UNKNOWN"},
+		}...)
+		msg = fmt.Sprintf("session created %s 0x0 %s %s 0x0 N/A N/A destination rule dnat-%s %s %s untrust dmz %d N/A(N/A) %s UNKNOWN UNKNOWN UNKNOWN",
+			conn, service, natConn, f.service, proto, policy, f.session, iface)
+	case 1:
+		event = "RT_FLOW_SESSION_CLOSE"
+		reason := []string{"TCP FIN", "TCP RST", "idle Timeout", "unset"}[rand.Intn(4)]
+		if f.proto == "udp" {
+			reason = "idle Timeout"
+		}
+		params = append([]SDParam{{"reason", reason}}, params...)
+		params = append(params, []SDParam{
+			{"nat-source-address", f.src}, {"nat-source-port", strconv.Itoa(f.sport)},
+			{"nat-destination-address", f.natDst}, {"nat-destination-port", strconv.Itoa(f.dport)},
+			{"nat-connection-tag", "0"},
+			{"src-nat-rule-type", "N/A"}, {"src-nat-rule-name", "N/A"},
+			{"dst-nat-rule-type", "destination rule"}, {"dst-nat-rule-name", "dnat-" + f.service},
+			{"protocol-id", proto}, {"policy-name", policy},
+			{"source-zone-name", "untrust"}, {"destination-zone-name", "dmz"},
+			{"session-id-32", strconv.Itoa(f.session)},
+			{"packets-from-client", strconv.Itoa(f.pktsTx)}, {"bytes-from-client", strconv.Itoa(f.bytesTx)},
+			{"packets-from-server", strconv.Itoa(f.pktsRx)}, {"bytes-from-server", strconv.Itoa(f.bytesRx)},
+			{"elapsed-time", strconv.Itoa(f.elapsed)},
+			{"application", "UNKNOWN"}, {"nested-application", "UNKNOWN"},
+			{"username", "N/A"}, {"roles", "N/A"}, {"packet-incoming-interface", iface}, {"encrypted", "UNKNOWN"},
+		}...)
+		msg = fmt.Sprintf("session closed %s: %s 0x0 %s %s 0x0 N/A N/A destination rule dnat-%s %s %s untrust dmz %d %d(%d) %d(%d) %d UNKNOWN UNKNOWN N/A(N/A) %s UNKNOWN",
+			reason, conn, service, natConn, f.service, proto, policy, f.session,
+			f.pktsTx, f.bytesTx, f.pktsRx, f.bytesRx, f.elapsed, iface)
+	default:
+		event = "RT_FLOW_SESSION_DENY"
+		policy = "deny-all"
+		params = append(params, []SDParam{
+			{"protocol-id", proto}, {"icmp-type", "0"}, {"policy-name", policy},
+			{"source-zone-name", "untrust"}, {"destination-zone-name", "dmz"},
+			{"application", "UNKNOWN"}, {"nested-application", "UNKNOWN"},
+			{"username", "N/A"}, {"roles", "N/A"}, {"packet-incoming-interface", iface},
+			{"encrypted", "No"}, {"reason", "policy deny"},
+		}...)
+		msg = fmt.Sprintf("session denied %s 0x0 %s %s(0) %s untrust dmz UNKNOWN UNKNOWN N/A(N/A) %s No policy deny",
+			conn, service, proto, policy, iface)
+	}
+
+	return &SyslogMessage{
+		Time:           time.Now(),
+		Priority:       Facilities["user"]*8 + 6,
+		Hostname:       fmt.Sprintf("srx-%02d", n),
+		AppName:        "RT_FLOW",
+		MsgID:          event,
+		StructuredData: []SDElement{{ID: JuniperSRXSDID, Params: params}},
+		Msg:            msg,
+		BSDMsg:         event + ": " + msg,
+	}
+}
+
+// NewVendorFirewallEvent generates an event in one of the FirewallVendors
+// dialects
+func NewVendorFirewallEvent(vendor string) (*SyslogMessage, error) {
+	switch vendor {
+	case "asa":
+		return NewASAEvent(), nil
+	case "panos":
+		return NewPANOSEvent(), nil
+	case "fortigate":
+		return NewFortiGateEvent(), nil
+	case "checkpoint":
+		return NewCheckPointEvent(), nil
+	case "srx":
+		return NewSRXEvent(), nil
+	}
+	return nil, fmt.Errorf("unknown firewall vendor: %s", vendor)
+}
@@ -6,6 +6,7 @@ package generators
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/bytedance/sonic"
@@ -48,9 +49,10 @@ var SamplePorts = []uint16{
 	22, 23, 25, 53, 80, 443, 445, 993, 995, 3306, 3389, 5432, 6379, 8080, 8443, 9200,
 }
 
-// GenerateJSONEvent generates a random JSON event for testing
-func GenerateJSONEvent() ([]byte, error) {
-	event := map[string]interface{}{
+// NewJSONEvent generates a random event as a field map, for callers that need
+// to inspect fields before encoding
+func NewJSONEvent() map[string]interface{} {
+	return map[string]interface{}{
 		"timestamp":   time.Now().UTC().Format(time.RFC3339Nano),
 		"source_ip":   SampleIPs[rand.Intn(len(SampleIPs))],
 		"dest_ip":     SampleIPs[rand.Intn(len(SampleIPs))],
@@ -65,14 +67,17 @@ func GenerateJSONEvent() ([]byte, error) {
 		"duration_ms": rand.Intn(5000),
 		"session_id":  fmt.Sprintf("sess_%d", rand.Int63()),
 	}
+}
 
-	return sonic.Marshal(event)
+// GenerateJSONEvent generates a random JSON event for testing
+func GenerateJSONEvent() ([]byte, error) {
+	return sonic.Marshal(NewJSONEvent())
 }
 
-// GenerateSyslogMessage generates a syslog message
-func GenerateSyslogMessage(rfc string) string {
-	now := time.Now()
-	hostname := fmt.Sprintf("server%d", rand.Intn(10)+1)
+// NewSyslogEvent generates a generic auth/system syslog event
+func NewSyslogEvent() *SyslogMessage {
+	n := rand.Intn(10) + 1
+	hostname := fmt.Sprintf("server%d", n)
 	process := SampleProcesses[rand.Intn(len(SampleProcesses))]
 	pid := rand.Intn(65535)
 
@@ -81,38 +86,53 @@ func GenerateSyslogMessage(rfc string) string {
 	// severity: 4 (warning), 5 (notice), 6 (info)
 	priority := ([]int{1, 4}[rand.Intn(2)] * 8) + rand.Intn(3) + 4
 
-	messages := []string{
-		fmt.Sprintf("User %s logged in from %s", SampleUsernames[rand.Intn(len(SampleUsernames))], SampleIPs[rand.Intn(len(SampleIPs))]),
-		fmt.Sprintf("Connection from %s port %d", SampleIPs[rand.Intn(len(SampleIPs))], rand.Intn(65535)),
-		fmt.Sprintf("Failed password for %s from %s", SampleUsernames[rand.Intn(len(SampleUsernames))], SampleIPs[rand.Intn(len(SampleIPs))]),
-		fmt.Sprintf("Session opened for user %s", SampleUsernames[rand.Intn(len(SampleUsernames))]),
-		fmt.Sprintf("Session closed for user %s", SampleUsernames[rand.Intn(len(SampleUsernames))]),
-		fmt.Sprintf("Accepted publickey for %s from %s", SampleUsernames[rand.Intn(len(SampleUsernames))], SampleIPs[rand.Intn(len(SampleIPs))]),
-		fmt.Sprintf("Process %s started with PID %d", process, pid),
-		fmt.Sprintf("Service %s reloaded", process),
+	user := SampleUsernames[rand.Intn(len(SampleUsernames))]
+	ip := SampleIPs[rand.Intn(len(SampleIPs))]
+	port := rand.Intn(65535)
+
+	m := &SyslogMessage{
+		Time:     time.Now(),
+		Priority: priority,
+		Hostname: hostname,
+		AppName:  process,
+		ProcID:   strconv.Itoa(pid),
 	}
-	msg := messages[rand.Intn(len(messages))]
-
-	switch rfc {
-	case "5424":
-		// RFC5424: <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [STRUCTURED-DATA] MSG
-		return fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
-			priority,
-			now.Format("2006-01-02T15:04:05.000000Z07:00"),
-			hostname,
-			process,
-			pid,
-			msg,
-		)
+	var params []SDParam
+	switch rand.Intn(8) {
+	case 0:
+		m.MsgID, m.Msg = "LOGIN", fmt.Sprintf("User %s logged in from %s", user, ip)
+		params = []SDParam{{"user", user}, {"src", ip}}
+	case 1:
+		m.MsgID, m.Msg = "CONNECT", fmt.Sprintf("Connection from %s port %d", ip, port)
+		params = []SDParam{{"src", ip}, {"spt", strconv.Itoa(port)}}
+	case 2:
+		m.MsgID, m.Msg = "AUTH_FAIL", fmt.Sprintf("Failed password for %s from %s", user, ip)
+		params = []SDParam{{"user", user}, {"src", ip}, {"method", "password"}}
+	case 3:
+		m.MsgID, m.Msg = "SESSION_OPEN", fmt.Sprintf("Session opened for user %s", user)
+		params = []SDParam{{"user", user}}
+	case 4:
+		m.MsgID, m.Msg = "SESSION_CLOSE", fmt.Sprintf("Session closed for user %s", user)
+		params = []SDParam{{"user", user}}
+	case 5:
+		m.MsgID, m.Msg = "AUTH_OK", fmt.Sprintf("Accepted publickey for %s from %s", user, ip)
+		params = []SDParam{{"user", user}, {"src", ip}, {"method", "publickey"}}
+	case 6:
+		m.MsgID, m.Msg = "PROC_START", fmt.Sprintf("Process %s started with PID %d", process, pid)
+		params = []SDParam{{"exe", "/usr/sbin/" + process}, {"pid", strconv.Itoa(pid)}}
 	default:
-		// RFC3164: <PRI>TIMESTAMP HOSTNAME TAG: MESSAGE
-		return fmt.Sprintf("<%d>%s %s %s[%d]: %s",
-			priority,
-			now.Format("Jan  2 15:04:05"),
-			hostname,
-			process,
-			pid,
-			msg,
-		)
+		m.MsgID, m.Msg = "RELOAD", fmt.Sprintf("Service %s reloaded", process)
+		params = []SDParam{{"unit", process + ".service"}}
+	}
+	m.StructuredData = []SDElement{
+		{ID: sdID("event"), Params: params},
+		originSD(fmt.Sprintf("10.0.1.%d", n), process),
+		metaSD(),
 	}
+	return m
+}
+
+// GenerateSyslogMessage generates a generic syslog message
+func GenerateSyslogMessage(opts Options) string {
+	return NewSyslogEvent().Apply(opts).Render(opts)
 }
@@ -0,0 +1,312 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LEEFSources lists the generators that can be rendered as LEEF
+var LEEFSources = []string{"firewall", "ids", "tms", "json"}
+
+// leefTimeLayout renders devTime in leefTimeFormat, the Java date pattern
+// QRadar parses it with
+const (
+	leefTimeLayout = "Jan 02 2006 15:04:05.000 MST"
+	leefTimeFormat = "MMM dd yyyy HH:mm:ss.SSS z"
+)
+
+// LEEFFormat selects the LEEF version and the LEEF 2.0 attribute delimiter.
+// The zero value is LEEF 2.0 with tab delimiters.
+type LEEFFormat struct {
+	// Version is "1.0" or "2.0"; "" means 2.0
+	Version string
+	// Delimiter separates attributes in LEEF 2.0; 0 means tab. LEEF 1.0
+	// always uses tabs.
+	Delimiter byte
+}
+
+// ParseLEEFFormat parses a LEEF version and a LEEF 2.0 delimiter given as a
+// single character, "tab", or a hex code such as "x09" or "0x5E". Delimiters
+// must be ASCII punctuation or a control character other than CR, LF and NUL.
+func ParseLEEFFormat(version, delimiter string) (LEEFFormat, error) {
+	var f LEEFFormat
+	switch version {
+	case "1.0", "1":
+		f.Version = "1.0"
+	case "2.0", "2", "":
+		f.Version = "2.0"
+	default:
+		return f, fmt.Errorf("invalid LEEF version: %s (must be 1.0 or 2.0)", version)
+	}
+
+	switch {
+	case delimiter == "" || delimiter == "tab" || delimiter == `\t`:
+		f.Delimiter = '\t'
+	case len(delimiter) == 1:
+		f.Delimiter = delimiter[0]
+	default:
+		lower := strings.ToLower(delimiter)
+		hex, ok := strings.CutPrefix(lower, "0x")
+		if !ok {
+			hex, ok = strings.CutPrefix(lower, "x")
+		}
+		n, err := strconv.ParseUint(hex, 16, 8)
+		if !ok || err != nil {
+			return f, fmt.Errorf("invalid LEEF delimiter: %s (must be one character, tab, or a hex code like x09)", delimiter)
+		}
+		f.Delimiter = byte(n)
+	}
+	switch f.Delimiter {
+	case '|', '=', ' ', '\n', '\r', 0:
+		return f, fmt.Errorf("invalid LEEF delimiter: %q cannot separate attributes", f.Delimiter)
+	}
+	if f.Delimiter > 0x7e {
+		return f, fmt.Errorf("invalid LEEF delimiter: 0x%02x is not ASCII", f.Delimiter)
+	}
+	// Attribute keys are made of letters, digits and underscores, so those
+	// would be ambiguous as delimiters
+	if d := f.Delimiter; d == '_' || d >= '0' && d <= '9' || d >= 'A' && d <= 'Z' || d >= 'a' && d <= 'z' {
+		return f, fmt.Errorf("invalid LEEF delimiter: %q can appear in attribute keys", f.Delimiter)
+	}
+	if f.Version == "1.0" && f.Delimiter != '\t' {
+		return f, fmt.Errorf("LEEF 1.0 only supports tab delimiters; use LEEF 2.0 for %q", f.Delimiter)
+	}
+	return f, nil
+}
+
+// LEEFAttribute is one key=value pair of a LEEF event
+type LEEFAttribute struct {
+	Key   string
+	Value string
+}
+
+// LEEFEvent is an IBM QRadar Log Event Extended Format event
+type LEEFEvent struct {
+	Vendor         string
+	Product        string
+	ProductVersion string
+	EventID        string
+	Attributes     []LEEFAttribute
+}
+
+var leefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+
+// Render renders the event as LEEF:1.0|Vendor|Product|Version|EventID|attrs
+// or LEEF:2.0|Vendor|Product|Version|EventID|Delimiter|attrs. LEEF has no
+// escaping for attribute values, so the delimiter and line breaks in values
+// are replaced by spaces. Empty values are left out.
+func (e *LEEFEvent) Render(f LEEFFormat) string {
+	delim := f.Delimiter
+	if delim == 0 || f.Version == "1.0" {
+		delim = '\t'
+	}
+	version := f.Version
+	if version == "" {
+		version = "2.0"
+	}
+
+	var b strings.Builder
+	b.WriteString("LEEF:")
+	b.WriteString(version)
+	for _, h := range []string{e.Vendor, e.Product, e.ProductVersion, e.EventID} {
+		b.WriteByte('|')
+		b.WriteString(leefHeaderEscaper.Replace(h))
+	}
+	b.WriteByte('|')
+	if version == "2.0" {
+		// Whitespace and control characters are written as hex codes
+		if delim <= ' ' {
+			fmt.Fprintf(&b, "x%02X", delim)
+		} else {
+			b.WriteByte(delim)
+		}
+		b.WriteByte('|')
+	}
+
+	valueEscaper := strings.NewReplacer(string(delim), " ", "\r\n", " ", "\n", " ", "\r", " ")
+	first := true
+	for _, a := range e.Attributes {
+		if a.Value == "" {
+			continue
+		}
+		if !first {
+			b.WriteByte(delim)
+		}
+		first = false
+		b.WriteString(a.Key)
+		b.WriteByte('=')
+		b.WriteString(valueEscaper.Replace(a.Value))
+	}
+	return b.String()
+}
+
+// leefTime returns the devTime and devTimeFormat attributes for t
+func leefTime(t time.Time) []LEEFAttribute {
+	return []LEEFAttribute{
+		{"devTime", t.UTC().Format(leefTimeLayout)},
+		{"devTimeFormat", leefTimeFormat},
+	}
+}
+
+// firewallLEEF maps a firewall event to LEEF
+func firewallLEEF(m *SyslogMessage) *LEEFEvent {
+	action := m.param("action")
+	return &LEEFEvent{
+		Vendor:         "Canonical",
+		Product:        "UFW",
+		ProductVersion: "0.36",
+		EventID:        "UFW_" + action,
+		Attributes: append(leefTime(m.Time),
+			LEEFAttribute{"cat", "Firewall " + strings.ToLower(action)},
+			LEEFAttribute{"sev", "5"},
+			LEEFAttribute{"src", m.param("src")},
+			LEEFAttribute{"dst", m.param("dst")},
+			LEEFAttribute{"srcPort", m.param("spt")},
+			LEEFAttribute{"dstPort", m.param("dpt")},
+			LEEFAttribute{"proto", m.param("proto")},
+			LEEFAttribute{"action", action},
+			LEEFAttribute{"inIface", m.param("in")},
+			LEEFAttribute{"outIface", m.param("out")},
+		),
+	}
+}
+
+// idsLEEF maps an IDS alert to LEEF
+func idsLEEF(m *SyslogMessage) *LEEFEvent {
+	sig := m.param("msg")
+	return &LEEFEvent{
+		Vendor:         "Snort",
+		Product:        "Snort IDS",
+		ProductVersion: "2.9.20",
+		EventID:        m.param("sid"),
+		Attributes: append(leefTime(m.Time),
+			LEEFAttribute{"cat", IDSCategory(sig)},
+			LEEFAttribute{"sev", strconv.Itoa(idsSeverity(sig))},
+			LEEFAttribute{"src", m.param("src")},
+			LEEFAttribute{"dst", m.param("dst")},
+			LEEFAttribute{"srcPort", m.param("spt")},
+			LEEFAttribute{"dstPort", m.param("dpt")},
+			LEEFAttribute{"proto", m.param("proto")},
+			LEEFAttribute{"action", "alert"},
+			LEEFAttribute{"signature", sig},
+			LEEFAttribute{"gid", m.param("gid")},
+			LEEFAttribute{"rev", m.param("rev")},
+		),
+	}
+}
+
+// tmsLEEF maps a TMS blocked_host event to LEEF
+func tmsLEEF(m *SyslogMessage) *LEEFEvent {
+	sev := "6"
+	if m.param("blacklisted") == "yes" {
+		sev = "8"
+	}
+	return &LEEFEvent{
+		Vendor:         "Arbor",
+		Product:        "TMS",
+		ProductVersion: "6.4",
+		EventID:        "blocked_host",
+		Attributes: append(leefTime(m.Time),
+			LEEFAttribute{"cat", "DDoS mitigation"},
+			LEEFAttribute{"sev", sev},
+			LEEFAttribute{"src", m.param("src")},
+			LEEFAttribute{"srcPort", m.param("spt")},
+			LEEFAttribute{"dstPort", m.param("dpt")},
+			LEEFAttribute{"proto", protocolName(m.param("proto"))},
+			LEEFAttribute{"action", m.param("countermeasure")},
+			LEEFAttribute{"policy", m.param("mitigation")},
+			LEEFAttribute{"resource", m.param("prefix")},
+			LEEFAttribute{"reason", m.param("reason")},
+			LEEFAttribute{"rule", m.param("rule")},
+			LEEFAttribute{"blacklisted", m.param("blacklisted")},
+		),
+	}
+}
+
+// jsonLEEF maps a generic JSON event to LEEF
+func jsonLEEF(e map[string]interface{}) *LEEFEvent {
+	str := func(k string) string { return fmt.Sprint(e[k]) }
+	sev := "3"
+	switch str("status") {
+	case "failed", "error":
+		sev = "6"
+	case "timeout":
+		sev = "4"
+	}
+	var attrs []LEEFAttribute
+	if ts, err := time.Parse(time.RFC3339Nano, str("timestamp")); err == nil {
+		attrs = leefTime(ts)
+	}
+	attrs = append(attrs,
+		LEEFAttribute{"cat", str("action")},
+		LEEFAttribute{"sev", sev},
+		LEEFAttribute{"src", str("source_ip")},
+		LEEFAttribute{"dst", str("dest_ip")},
+		LEEFAttribute{"srcPort", str("source_port")},
+		LEEFAttribute{"dstPort", str("dest_port")},
+		LEEFAttribute{"proto", "TCP"},
+		LEEFAttribute{"usrName", str("username")},
+		LEEFAttribute{"action", str("action")},
+		LEEFAttribute{"outcome", str("status")},
+		LEEFAttribute{"srcBytes", str("bytes_sent")},
+		LEEFAttribute{"dstBytes", str("bytes_recv")},
+		LEEFAttribute{"process", str("process")},
+		LEEFAttribute{"durationMs", str("duration_ms")},
+		LEEFAttribute{"sessionId", str("session_id")},
+	)
+	switch str("action") {
+	case "login":
+		attrs = append(attrs, LEEFAttribute{"isLoginEvent", "true"})
+	case "logout":
+		attrs = append(attrs, LEEFAttribute{"isLogoutEvent", "true"})
+	}
+	return &LEEFEvent{
+		Vendor:         "fakedata",
+		Product:        "app",
+		ProductVersion: "1.0",
+		EventID:        str("action"),
+		Attributes:     attrs,
+	}
+}
+
+// NewLEEFEvent generates an event from one of LEEFSources as LEEF. For the
+// syslog-based sources the originating syslog message is returned too.
+func NewLEEFEvent(source string) (*LEEFEvent, *SyslogMessage, error) {
+	switch source {
+	case "firewall":
+		m := NewFirewallEvent()
+		return firewallLEEF(m), m, nil
+	case "ids":
+		m := NewIDSEvent()
+		return idsLEEF(m), m, nil
+	case "tms":
+		m := NewTMSEvent()
+		return tmsLEEF(m), m, nil
+	case "json":
+		return jsonLEEF(NewJSONEvent()), nil, nil
+	}
+	return nil, nil, fmt.Errorf("unknown LEEF source: %s", source)
+}
+
+// NewLEEFSyslogEvent generates a LEEF event wrapped in a syslog message from
+// the originating device, without an app name as QRadar expects
+func NewLEEFSyslogEvent(source string, f LEEFFormat) (*SyslogMessage, error) {
+	leef, m, err := NewLEEFEvent(source)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = NewSyslogEvent()
+	}
+	return &SyslogMessage{
+		Time:     m.Time,
+		Priority: m.Priority,
+		Hostname: m.Hostname,
+		Msg:      leef.Render(f),
+	}, nil
+}
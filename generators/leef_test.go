@@ -0,0 +1,55 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import "testing"
+
+func TestParseLEEFFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		delimiter string
+		want      LEEFFormat
+		wantErr   bool
+	}{
+		{"defaults", "", "", LEEFFormat{"2.0", '\t'}, false},
+		{"tab keyword", "2.0", "tab", LEEFFormat{"2.0", '\t'}, false},
+		{"escaped tab", "2", `\t`, LEEFFormat{"2.0", '\t'}, false},
+		{"caret", "2.0", "^", LEEFFormat{"2.0", '^'}, false},
+		{"semicolon", "2.0", ";", LEEFFormat{"2.0", ';'}, false},
+		{"comma", "2.0", ",", LEEFFormat{"2.0", ','}, false},
+		{"tilde", "2.0", "~", LEEFFormat{"2.0", '~'}, false},
+		{"hex", "2.0", "x5E", LEEFFormat{"2.0", '^'}, false},
+		{"0x hex", "2.0", "0x7c", LEEFFormat{}, true},
+		{"hex control character", "2.0", "x01", LEEFFormat{"2.0", 0x01}, false},
+		{"LEEF 1.0 tab", "1.0", "tab", LEEFFormat{"1.0", '\t'}, false},
+		{"LEEF 1.0 caret", "1.0", "^", LEEFFormat{}, true},
+		{"bad version", "3.0", "tab", LEEFFormat{}, true},
+		{"lowercase letter", "2.0", "a", LEEFFormat{}, true},
+		{"uppercase letter", "2.0", "Z", LEEFFormat{}, true},
+		{"digit", "2.0", "7", LEEFFormat{}, true},
+		{"underscore", "2.0", "_", LEEFFormat{}, true},
+		{"hex letter", "2.0", "x61", LEEFFormat{}, true},
+		{"pipe", "2.0", "|", LEEFFormat{}, true},
+		{"equals", "2.0", "=", LEEFFormat{}, true},
+		{"space", "2.0", " ", LEEFFormat{}, true},
+		{"newline", "2.0", "x0A", LEEFFormat{}, true},
+		{"carriage return", "2.0", "x0d", LEEFFormat{}, true},
+		{"nul", "2.0", "x00", LEEFFormat{}, true},
+		{"non-ASCII", "2.0", "xA7", LEEFFormat{}, true},
+		{"bad hex", "2.0", "xZZ", LEEFFormat{}, true},
+		{"several characters", "2.0", "ab", LEEFFormat{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLEEFFormat(tt.version, tt.delimiter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLEEFFormat(%q, %q) error = %v, wantErr %v", tt.version, tt.delimiter, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ParseLEEFFormat(%q, %q) = %+v, want %+v", tt.version, tt.delimiter, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// MultilineKinds lists the multiline event kinds; "multiline" picks one at
+// random for every event
+var MultilineKinds = []string{"java", "python", "go", "json", "indent"}
+
+// javaFrame is one stack frame of a Java exception
+type javaFrame struct {
+	class, method, file string
+}
+
+var javaServiceFrames = []javaFrame{
+	{"com.example.orders.OrderService", "process", "OrderService.java"},
+	{"com.example.orders.OrderService", "lambda$submit$0", "OrderService.java"},
+	{"com.example.orders.OrderController", "create", "OrderController.java"},
+	{"com.example.billing.InvoiceRepository", "findByCustomer", "InvoiceRepository.java"},
+	{"com.example.billing.PaymentClient", "charge", "PaymentClient.java"},
+	{"com.example.inventory.StockLedger", "reserve", "StockLedger.java"},
+}
+
+var javaFrameworkFrames = []string{
+	"org.springframework.web.servlet.FrameworkServlet.service(FrameworkServlet.java:883)",
+	"org.springframework.web.servlet.DispatcherServlet.doDispatch(DispatcherServlet.java:1072)",
+	"org.springframework.transaction.interceptor.TransactionInterceptor.invoke(TransactionInterceptor.java:119)",
+	"org.apache.catalina.core.ApplicationFilterChain.doFilter(ApplicationFilterChain.java:166)",
+	"org.apache.tomcat.util.net.NioEndpoint$SocketProcessor.doRun(NioEndpoint.java:1791)",
+	"java.base/java.util.concurrent.ThreadPoolExecutor.runWorker(ThreadPoolExecutor.java:1136)",
+	"java.base/java.lang.Thread.run(Thread.java:840)",
+}
+
+var javaExceptions = []string{
+	`java.lang.NullPointerException: Cannot invoke "com.example.orders.Customer.getId()" because "customer" is null`,
+	"java.lang.IllegalStateException: Order 48213 is already settled",
+	"java.util.concurrent.TimeoutException: Payment gateway did not respond within 5000 ms",
+	"java.lang.IndexOutOfBoundsException: Index 3 out of bounds for length 3",
+	"org.springframework.dao.DataIntegrityViolationException: could not execute statement; constraint [uk_invoice_number]",
+}
+
+var javaCauses = []string{
+	"java.sql.SQLTransientConnectionException: HikariPool-1 - Connection is not available, request timed out after 30000ms.",
+	"java.net.SocketTimeoutException: Read timed out",
+	"java.io.IOException: Broken pipe",
+}
+
+// javaStackTrace renders a logback-style error line with an exception,
+// frames and optionally a "Caused by" chain
+func javaStackTrace(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s ERROR %d --- [http-nio-8080-exec-%d] c.e.orders.OrderService : Request failed for order %d\n",
+		now.Format("2006-01-02 15:04:05.000"), rand.Intn(30000)+1000, rand.Intn(20)+1, rand.Intn(90000)+10000)
+	b.WriteString(javaExceptions[rand.Intn(len(javaExceptions))])
+	for i, n := 0, rand.Intn(4)+2; i < n; i++ {
+		f := javaServiceFrames[rand.Intn(len(javaServiceFrames))]
+		fmt.Fprintf(&b, "\n\tat %s.%s(%s:%d)", f.class, f.method, f.file, rand.Intn(400)+20)
+	}
+	for i, n := 0, rand.Intn(4)+2; i < n; i++ {
+		b.WriteString("\n\tat " + javaFrameworkFrames[rand.Intn(len(javaFrameworkFrames))])
+	}
+	if rand.Intn(2) == 0 {
+		b.WriteString("\nCaused by: " + javaCauses[rand.Intn(len(javaCauses))])
+		f := javaServiceFrames[rand.Intn(len(javaServiceFrames))]
+		fmt.Fprintf(&b, "\n\tat %s.%s(%s:%d)", f.class, f.method, f.file, rand.Intn(400)+20)
+		fmt.Fprintf(&b, "\n\t... %d more", rand.Intn(40)+5)
+	}
+	return b.String()
+}
+
+// pythonFrame is one traceback entry: file, function and source line
+type pythonFrame struct {
+	file, function, source string
+}
+
+var pythonFrames = []pythonFrame{
+	{"/app/worker/tasks.py", "process_payment", "result = gateway.charge(order.total, order.card_token)"},
+	{"/app/worker/gateway.py", "charge", "resp = self.session.post(self.url, json=payload, timeout=5)"},
+	{"/app/worker/models.py", "load_order", "return Order.objects.get(pk=order_id)"},
+	{"/app/api/views.py", "create_invoice", "invoice = build_invoice(customer, items)"},
+	{"/app/api/serializers.py", "validate", "total = sum(item['price'] * item['qty'] for item in items)"},
+	{"/usr/local/lib/python3.11/site-packages/requests/sessions.py", "request", "resp = self.send(prep, **send_kwargs)"},
+}
+
+var pythonErrors = []string{
+	"KeyError: 'price'",
+	"TypeError: unsupported operand type(s) for *: 'NoneType' and 'int'",
+	"requests.exceptions.ReadTimeout: HTTPSConnectionPool(host='pay.example.com', port=443): Read timed out. (read timeout=5)",
+	"ValueError: invalid literal for int() with base 10: 'N/A'",
+	"worker.models.Order.DoesNotExist: Order matching query does not exist.",
+}
+
+// pythonTraceback renders a logging error line followed by a traceback,
+// sometimes chained to a second exception
+func pythonTraceback(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s,%03d ERROR [%s] Unhandled exception in task %s",
+		now.Format("2006-01-02 15:04:05"), now.Nanosecond()/1e6,
+		[]string{"celery.worker", "api.views", "scheduler"}[rand.Intn(3)],
+		[]string{"process_payment", "send_invoice", "sync_inventory"}[rand.Intn(3)])
+	traceback := func() {
+		b.WriteString("\nTraceback (most recent call last):")
+		for i, n := 0, rand.Intn(3)+2; i < n; i++ {
+			f := pythonFrames[rand.Intn(len(pythonFrames))]
+			fmt.Fprintf(&b, "\n  File \"%s\", line %d, in %s\n    %s", f.file, rand.Intn(300)+10, f.function, f.source)
+		}
+		b.WriteString("\n" + pythonErrors[rand.Intn(len(pythonErrors))])
+	}
+	traceback()
+	if rand.Intn(3) == 0 {
+		b.WriteString("\n\nDuring handling of the above exception, another exception occurred:\n")
+		traceback()
+	}
+	return b.String()
+}
+
+// goFrames are application functions with the file they are defined in
+var goFrames = []struct {
+	function, file string
+}{
+	{"main.(*Server).handleOrder", "/app/server.go"},
+	{"main.(*Server).authenticate", "/app/auth.go"},
+	{"github.com/example/billing/internal/ledger.(*Ledger).Post", "/app/internal/ledger/ledger.go"},
+	{"github.com/example/billing/internal/store.(*Store).Get", "/app/internal/store/store.go"},
+}
+
+var goPanics = []string{
+	"runtime error: index out of range [3] with length 3",
+	"runtime error: invalid memory address or nil pointer dereference",
+	"assignment to entry in nil map",
+	"runtime error: slice bounds out of range [:12] with capacity 8",
+}
+
+// goPanic renders an http server panic with a goroutine stack dump
+func goPanic(now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s http: panic serving %s:%d: %s\ngoroutine %d [running]:",
+		now.Format("2006/01/02 15:04:05"), SampleIPs[rand.Intn(len(SampleIPs))], rand.Intn(64511)+1024,
+		goPanics[rand.Intn(len(goPanics))], rand.Intn(5000)+1)
+	b.WriteString("\nnet/http.(*conn).serve.func1()\n\t/usr/local/go/src/net/http/server.go:1898 +0xbe")
+	b.WriteString("\npanic({0x8b1c40?, 0xc0000a2f30?})\n\t/usr/local/go/src/runtime/panic.go:770 +0x132")
+	for i, n := 0, rand.Intn(3)+1; i < n; i++ {
+		f := goFrames[rand.Intn(len(goFrames))]
+		fmt.Fprintf(&b, "\n%s(0xc%09x, {0x%x, 0xc%09x})\n\t%s:%d +0x%x", f.function,
+			rand.Int63n(1<<36), rand.Intn(1<<24), rand.Int63n(1<<36), f.file, rand.Intn(400)+20, rand.Intn(0x400))
+	}
+	b.WriteString("\nnet/http.HandlerFunc.ServeHTTP(0xc0001b6000?, {0x9c5f18?, 0xc0001e20e0?}, 0x0?)\n\t/usr/local/go/src/net/http/server.go:2171 +0x29")
+	b.WriteString("\nnet/http.serverHandler.ServeHTTP({0xc0001d0c30?}, {0x9c5f18?, 0xc0001e20e0?}, 0x6?)\n\t/usr/local/go/src/net/http/server.go:3142 +0x8e")
+	b.WriteString("\nnet/http.(*conn).serve(0xc0001c2000, {0x9c6488, 0xc0001d0b40})\n\t/usr/local/go/src/net/http/server.go:2044 +0x5e8")
+	b.WriteString("\ncreated by net/http.(*Server).Serve in goroutine 1\n\t/usr/local/go/src/net/http/server.go:3290 +0x4b4")
+	return b.String()
+}
+
+// prettyJSON renders a JSON event indented over several lines
+func prettyJSON() string {
+	data, err := sonic.ConfigStd.MarshalIndent(NewJSONEvent(), "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// indentedLog renders a log line followed by indented continuation lines, as
+// written by schedulers, databases and many daemons
+func indentedLog(now time.Time) string {
+	ts := now.UTC().Format(time.RFC3339)
+	switch rand.Intn(3) {
+	case 0:
+		return fmt.Sprintf("%s WARN  scheduler: job %s exceeded its deadline\n    job_id=%d attempt=%d\n    duration=%ds limit=300s\n    next_run=%s",
+			ts, []string{"nightly-report", "invoice-export", "cache-warmup"}[rand.Intn(3)],
+			rand.Intn(9000)+1000, rand.Intn(5)+1, rand.Intn(600)+301, now.Add(time.Hour).UTC().Format(time.RFC3339))
+	case 1:
+		return fmt.Sprintf("# Time: %s\n# User@Host: %s[%s] @ [%s]\n# Query_time: %d.%06d  Lock_time: 0.%06d Rows_sent: %d  Rows_examined: %d\nSELECT o.id, o.total FROM orders o\n  JOIN customers c ON c.id = o.customer_id\n  WHERE c.region = 'emea' AND o.created_at > NOW() - INTERVAL 30 DAY;",
+			now.UTC().Format("2006-01-02T15:04:05.000000Z"), SampleUsernames[rand.Intn(len(SampleUsernames))],
+			SampleUsernames[rand.Intn(len(SampleUsernames))], SampleIPs[rand.Intn(len(SampleIPs))],
+			rand.Intn(20)+2, rand.Intn(1000000), rand.Intn(1000), rand.Intn(5000), rand.Intn(5000000)+5000)
+	default:
+		return fmt.Sprintf("%s ERROR deploy: rollout of %s failed\n  reason: readiness probe failed\n  pods:\n    - %s-%x (CrashLoopBackOff)\n    - %s-%x (Running, not ready)",
+			ts, SampleProcesses[rand.Intn(len(SampleProcesses))],
+			"web", rand.Intn(1<<20), "web", rand.Intn(1<<20))
+	}
+}
+
+// GenerateMultilineEvent generates a multiline event of the given kind,
+// one of MultilineKinds or "" for a random kind. Lines are separated by LF
+// and there is no trailing newline.
+func GenerateMultilineEvent(kind string) (string, error) {
+	if kind == "" {
+		kind = MultilineKinds[rand.Intn(len(MultilineKinds))]
+	}
+	now := time.Now()
+	switch kind {
+	case "java":
+		return javaStackTrace(now), nil
+	case "python":
+		return pythonTraceback(now), nil
+	case "go":
+		return goPanic(now), nil
+	case "json":
+		return prettyJSON(), nil
+	case "indent":
+		return indentedLog(now), nil
+	}
+	return "", fmt.Errorf("unknown multiline kind: %s", kind)
+}
+
+// multilineApps are the syslog app names of each multiline kind
+var multilineApps = map[string]string{
+	"java": "orders-api", "python": "celery", "go": "billing", "json": "audit", "indent": "scheduler",
+}
+
+// NewMultilineSyslogEvent wraps a multiline event of the given kind ("" for
+// random) in a syslog message
+func NewMultilineSyslogEvent(kind string) (*SyslogMessage, error) {
+	if kind == "" {
+		kind = MultilineKinds[rand.Intn(len(MultilineKinds))]
+	}
+	msg, err := GenerateMultilineEvent(kind)
+	if err != nil {
+		return nil, err
+	}
+	n := rand.Intn(10) + 1
+	app := multilineApps[kind]
+	return &SyslogMessage{
+		Time:     time.Now(),
+		Priority: 1*8 + 3, // user.err
+		Hostname: fmt.Sprintf("app%d", n),
+		AppName:  app,
+		ProcID:   fmt.Sprint(rand.Intn(30000) + 1000),
+		MsgID:    strings.ToUpper(kind),
+		StructuredData: []SDElement{
+			{ID: sdID("multiline"), Params: []SDParam{{"kind", kind}, {"lines", fmt.Sprint(strings.Count(msg, "\n") + 1)}}},
+			originSD(fmt.Sprintf("10.0.2.%d", n), app),
+			metaSD(),
+		},
+		Msg: msg,
+	}, nil
+}
@@ -0,0 +1,57 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// SyslogHeader holds the header fields of a generated syslog message
+type SyslogHeader struct {
+	Priority int
+	Hostname string
+	AppName  string
+}
+
+// Facility returns the syslog facility encoded in the priority
+func (h SyslogHeader) Facility() int {
+	return h.Priority / 8
+}
+
+// Severity returns the syslog severity encoded in the priority
+func (h SyslogHeader) Severity() int {
+	return h.Priority % 8
+}
+
+var (
+	// <PRI>1 TIMESTAMP HOSTNAME APP-NAME ...
+	rfc5424Header = regexp.MustCompile(`^<(\d{1,3})>1 \S+ (\S+) (\S+)`)
+	// <PRI>TIMESTAMP [HOSTNAME] TAG[PID]: ... where TIMESTAMP is
+	// "Mmm dd [yyyy] hh:mm:ss [ZONE]" or RFC 3339, as in the header variants
+	rfc3164Header = regexp.MustCompile(`^<(\d{1,3})>(?:\d{4}-\d{2}-\d{2}T\S+|[A-Z][a-z]{2} +\d{1,2}(?: \d{4})? \d{2}:\d{2}:\d{2}(?: [A-Z]{2,5}| [+-]\d{4})?) (?:([^\s\[\]:]+) )?([^\s\[:]+)(?:\[\d*\])?:`)
+)
+
+// ParseSyslogHeader extracts the priority, hostname and app name from a
+// syslog message produced by the generators. NILVALUE ("-") fields are
+// returned as empty strings.
+func ParseSyslogHeader(line string) (SyslogHeader, bool) {
+	m := rfc5424Header.FindStringSubmatch(line)
+	if m == nil {
+		m = rfc3164Header.FindStringSubmatch(line)
+	}
+	if m == nil {
+		return SyslogHeader{}, false
+	}
+
+	pri, _ := strconv.Atoi(m[1])
+	h := SyslogHeader{Priority: pri, Hostname: m[2], AppName: m[3]}
+	if h.Hostname == "-" {
+		h.Hostname = ""
+	}
+	if h.AppName == "-" {
+		h.AppName = ""
+	}
+	return h, true
+}
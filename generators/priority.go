@@ -0,0 +1,143 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Weighted is a value picked with probability proportional to Weight
+type Weighted struct {
+	Value  int
+	Weight int
+}
+
+// Facilities maps syslog facility keywords to codes (RFC 5424 table 1)
+var Facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"ntp": 12, "security": 13, "console": 14, "solaris-cron": 15,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// Severities maps syslog severity keywords, including common aliases, to
+// codes (RFC 5424 table 2)
+var Severities = map[string]int{
+	"emerg": 0, "emergency": 0, "panic": 0, "alert": 1, "crit": 2, "critical": 2,
+	"err": 3, "error": 3, "warning": 4, "warn": 4, "notice": 5,
+	"info": 6, "informational": 6, "debug": 7,
+}
+
+// Syslog header variants. Real devices deviate from the standard headers;
+// these reproduce the common deviations.
+const (
+	// HeaderStandard is the plain RFC 3164 or RFC 5424 header
+	HeaderStandard = iota
+	// HeaderNoHostname omits the hostname (NILVALUE in RFC 5424)
+	HeaderNoHostname
+	// HeaderISO uses an RFC 3339 timestamp inside an RFC 3164 header
+	HeaderISO
+	// HeaderYear adds the year to the RFC 3164 timestamp: "Jan _2 2006 15:04:05"
+	HeaderYear
+	// HeaderTZ stamps a non-UTC zone: a zone suffix in RFC 3164, an offset in RFC 5424
+	HeaderTZ
+)
+
+// HeaderVariants maps header variant names to their constants
+var HeaderVariants = map[string]int{
+	"standard":    HeaderStandard,
+	"no-hostname": HeaderNoHostname,
+	"iso":         HeaderISO,
+	"year":        HeaderYear,
+	"tz":          HeaderTZ,
+}
+
+// headerZones are the zones used by HeaderTZ
+var headerZones = []*time.Location{
+	time.FixedZone("UTC", 0),
+	time.FixedZone("EST", -5*3600),
+	time.FixedZone("PDT", -7*3600),
+	time.FixedZone("CET", 3600),
+	time.FixedZone("IST", 5*3600+1800),
+	time.FixedZone("JST", 9*3600),
+}
+
+// MaxWeight is the largest weight ParseWeights accepts, which keeps the sum
+// of any mix well clear of overflow
+const MaxWeight = 1000000
+
+// ParseWeights parses a weighted mix such as "auth:3,local0:1,user". Names
+// are looked up in names; numbers up to max are accepted as well unless max
+// is negative. A missing weight counts as 1; weights range from 0 to
+// MaxWeight.
+func ParseWeights(spec string, names map[string]int, max int) ([]Weighted, error) {
+	var ws []Weighted
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, weight := item, 1
+		if i := strings.LastIndex(item, ":"); i >= 0 {
+			w, err := strconv.Atoi(item[i+1:])
+			if err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight in %q", item)
+			}
+			if w > MaxWeight {
+				return nil, fmt.Errorf("weight in %q exceeds %d", item, MaxWeight)
+			}
+			name, weight = item[:i], w
+		}
+		value, ok := names[strings.ToLower(name)]
+		if !ok {
+			n, err := strconv.Atoi(name)
+			if err != nil || n < 0 || n > max {
+				if max < 0 {
+					return nil, fmt.Errorf("unknown value %q (must be one of %s)", name, weightNames(names))
+				}
+				return nil, fmt.Errorf("unknown value %q (must be one of %s or 0-%d)", name, weightNames(names), max)
+			}
+			value = n
+		}
+		if weight > 0 {
+			ws = append(ws, Weighted{Value: value, Weight: weight})
+		}
+	}
+	if len(ws) == 0 && strings.TrimSpace(spec) != "" {
+		return nil, fmt.Errorf("no positive weights in %q", spec)
+	}
+	return ws, nil
+}
+
+// weightNames lists the keys of names for error messages
+func weightNames(names map[string]int) string {
+	keys := make([]string, 0, len(names))
+	for k := range names {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// pickWeighted returns a value from ws at random according to the weights
+func pickWeighted(ws []Weighted) int {
+	total := 0
+	for _, w := range ws {
+		total += w.Weight
+	}
+	n := rand.Intn(total)
+	for _, w := range ws {
+		if n < w.Weight {
+			return w.Value
+		}
+		n -= w.Weight
+	}
+	return ws[len(ws)-1].Value
+}
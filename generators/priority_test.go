@@ -0,0 +1,48 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		max     int
+		want    []Weighted
+		wantErr bool
+	}{
+		{"empty", "", 7, nil, false},
+		{"names", "info:3,warn:1", 7, []Weighted{{6, 3}, {4, 1}}, false},
+		{"case and spaces", " INFO:2 , Err ", 7, []Weighted{{6, 2}, {3, 1}}, false},
+		{"numbers", "6:2,0", 7, []Weighted{{6, 2}, {0, 1}}, false},
+		{"missing weight", "debug", 7, []Weighted{{7, 1}}, false},
+		{"zero weight dropped", "info:0,err:1", 7, []Weighted{{3, 1}}, false},
+		{"all zero", "info:0,err:0", 7, nil, true},
+		{"max weight", "info:1000000", 7, []Weighted{{6, MaxWeight}}, false},
+		{"weight too large", "info:1000001", 7, nil, true},
+		{"overflow", "info:9223372036854775807,warn:1", 7, nil, true},
+		{"weight out of int range", "info:99999999999999999999", 7, nil, true},
+		{"negative weight", "info:-1", 7, nil, true},
+		{"bad weight", "info:x", 7, nil, true},
+		{"unknown name", "verbose", 7, nil, true},
+		{"number above max", "8", 7, nil, true},
+		{"negative number", "-1", 7, nil, true},
+		{"numbers not allowed", "1", -1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWeights(tt.spec, Severities, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWeights(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWeights(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
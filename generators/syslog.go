@@ -6,6 +6,7 @@ package generators
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 	"time"
 )
 
@@ -87,15 +88,14 @@ var AttackPorts = []int{
 // Protocols: 1=ICMP, 6=TCP, 17=UDP
 var Protocols = []int{1, 6, 17}
 
-// GenerateTMSSyslog generates a TMS (Threat Mitigation System) syslog message
+// NewTMSEvent generates a TMS (Threat Mitigation System) blocked_host event
 // Format: <14>May 11 19:43:09 tms6ash tms[24536]: blocked_host addr=IP, src_port=N, dst_port=N, protocol=N, mitigation=NAME, prefixes=PREFIX, countermeasure=TYPE, reason=REASON, rule=N, blacklisted=BOOL
-func GenerateTMSSyslog() string {
-	now := time.Now()
-
+func NewTMSEvent() *SyslogMessage {
 	// Priority 14 = facility 1 (user) * 8 + severity 6 (info)
 	priority := 14
 
-	hostname := TMSHostnames[rand.Intn(len(TMSHostnames))]
+	n := rand.Intn(len(TMSHostnames))
+	hostname := TMSHostnames[n]
 	pid := rand.Intn(50000) + 10000
 
 	srcIP := MaliciousIPs[rand.Intn(len(MaliciousIPs))]
@@ -112,31 +112,55 @@ func GenerateTMSSyslog() string {
 		blacklisted = "yes"
 	}
 
-	// RFC3164 format
-	return fmt.Sprintf("<%d>%s %s tms[%d]: blocked_host addr=%s, src_port=%d, dst_port=%d, protocol=%d, mitigation=%s, prefixes=%s, countermeasure=%s, reason=%s, rule=%d, blacklisted=%s",
-		priority,
-		now.Format("Jan  2 15:04:05"),
-		hostname,
-		pid,
-		srcIP,
-		srcPort,
-		dstPort,
-		protocol,
-		mitigation,
-		prefix,
-		countermeasure,
-		reason,
-		rule,
-		blacklisted,
-	)
+	return &SyslogMessage{
+		Time:     time.Now(),
+		Priority: priority,
+		Hostname: hostname,
+		AppName:  "tms",
+		ProcID:   strconv.Itoa(pid),
+		MsgID:    "blocked_host",
+		StructuredData: []SDElement{
+			{ID: sdID("tms"), Params: []SDParam{
+				{"src", srcIP},
+				{"spt", strconv.Itoa(srcPort)},
+				{"dpt", strconv.Itoa(dstPort)},
+				{"proto", strconv.Itoa(protocol)},
+				{"mitigation", mitigation},
+				{"prefix", prefix},
+				{"countermeasure", countermeasure},
+				{"reason", reason},
+				{"rule", strconv.Itoa(rule)},
+				{"blacklisted", blacklisted},
+			}},
+			originSD(fmt.Sprintf("10.255.0.%d", n+1), "tms"),
+			metaSD(),
+		},
+		Msg: fmt.Sprintf("blocked_host addr=%s, src_port=%d, dst_port=%d, protocol=%d, mitigation=%s, prefixes=%s, countermeasure=%s, reason=%s, rule=%d, blacklisted=%s",
+			srcIP,
+			srcPort,
+			dstPort,
+			protocol,
+			mitigation,
+			prefix,
+			countermeasure,
+			reason,
+			rule,
+			blacklisted,
+		),
+	}
 }
 
-// GenerateFirewallSyslog generates firewall-style syslog messages
-func GenerateFirewallSyslog() string {
-	now := time.Now()
+// GenerateTMSSyslog generates a TMS syslog message
+func GenerateTMSSyslog(opts Options) string {
+	return NewTMSEvent().Apply(opts).Render(opts)
+}
+
+// NewFirewallEvent generates a UFW/iptables style firewall event
+func NewFirewallEvent() *SyslogMessage {
 	priority := []int{12, 13, 14}[rand.Intn(3)] // Various info/notice priorities
 
-	hostname := fmt.Sprintf("fw-%02d", rand.Intn(20)+1)
+	n := rand.Intn(20) + 1
+	hostname := fmt.Sprintf("fw-%02d", n)
 	pid := rand.Intn(10000) + 1000
 
 	srcIP := MaliciousIPs[rand.Intn(len(MaliciousIPs))]
@@ -153,66 +177,117 @@ func GenerateFirewallSyslog() string {
 
 	protocols := []string{"TCP", "UDP", "ICMP"}
 	proto := protocols[rand.Intn(len(protocols))]
+	prefix := "[UFW " + action + "]"
 
-	return fmt.Sprintf("<%d>%s %s kernel[%d]: [UFW %s] IN=%s OUT=%s SRC=%s DST=%s LEN=%d TOS=0x00 PREC=0x00 TTL=%d ID=%d PROTO=%s SPT=%d DPT=%d",
-		priority,
-		now.Format("Jan  2 15:04:05"),
-		hostname,
-		pid,
-		action,
-		inIface,
-		outIface,
-		srcIP,
-		dstIP,
-		rand.Intn(1500)+40,
-		rand.Intn(64)+1,
-		rand.Intn(65535),
-		proto,
-		srcPort,
-		dstPort,
-	)
+	return &SyslogMessage{
+		Time:     time.Now(),
+		Priority: priority,
+		Hostname: hostname,
+		AppName:  "kernel",
+		ProcID:   strconv.Itoa(pid),
+		MsgID:    "UFW_" + action,
+		StructuredData: []SDElement{
+			{ID: sdID("fw"), Params: []SDParam{
+				{"prefix", prefix},
+				{"action", action},
+				{"proto", proto},
+				{"src", srcIP},
+				{"spt", strconv.Itoa(srcPort)},
+				{"dst", dstIP},
+				{"dpt", strconv.Itoa(dstPort)},
+				{"in", inIface},
+				{"out", outIface},
+			}},
+			originSD(fmt.Sprintf("10.254.0.%d", n), "ufw"),
+			metaSD(),
+		},
+		Msg: fmt.Sprintf("%s IN=%s OUT=%s SRC=%s DST=%s LEN=%d TOS=0x00 PREC=0x00 TTL=%d ID=%d PROTO=%s SPT=%d DPT=%d",
+			prefix,
+			inIface,
+			outIface,
+			srcIP,
+			dstIP,
+			rand.Intn(1500)+40,
+			rand.Intn(64)+1,
+			rand.Intn(65535),
+			proto,
+			srcPort,
+			dstPort,
+		),
+	}
 }
 
-// GenerateIDSSyslog generates IDS/IPS style alerts
-func GenerateIDSSyslog() string {
-	now := time.Now()
+// GenerateFirewallSyslog generates firewall-style syslog messages
+func GenerateFirewallSyslog(opts Options) string {
+	return NewFirewallEvent().Apply(opts).Render(opts)
+}
+
+// IDSSignatures are the Emerging Threats rule names used for IDS alerts
+var IDSSignatures = []string{
+	"ET SCAN Potential SSH Scan",
+	"ET EXPLOIT Possible SQL Injection Attempt",
+	"ET TROJAN Known Malware CnC",
+	"ET DOS Possible NTP DDoS Amplification",
+	"ET POLICY Outbound SSH Connection",
+	"ET SCAN Nmap OS Detection Probe",
+	"ET ATTACK_RESPONSE Suspicious 200 OK",
+	"ET WEB_SERVER SQL Injection Attempt",
+	"ET MALWARE Ransomware CnC Beacon",
+	"ET SCAN Masscan Detected",
+}
+
+// NewIDSEvent generates a Snort fast-alert style IDS event
+func NewIDSEvent() *SyslogMessage {
 	priority := 10 // security/auth warning
 
-	hostname := fmt.Sprintf("ids-%02d", rand.Intn(10)+1)
+	n := rand.Intn(10) + 1
+	hostname := fmt.Sprintf("ids-%02d", n)
 
 	srcIP := MaliciousIPs[rand.Intn(len(MaliciousIPs))]
 	dstIP := SampleIPs[rand.Intn(len(SampleIPs))]
 	srcPort := rand.Intn(65535-1024) + 1024
 	dstPort := AttackPorts[rand.Intn(len(AttackPorts))]
 
-	signatures := []string{
-		"ET SCAN Potential SSH Scan",
-		"ET EXPLOIT Possible SQL Injection Attempt",
-		"ET TROJAN Known Malware CnC",
-		"ET DOS Possible NTP DDoS Amplification",
-		"ET POLICY Outbound SSH Connection",
-		"ET SCAN Nmap OS Detection Probe",
-		"ET ATTACK_RESPONSE Suspicious 200 OK",
-		"ET WEB_SERVER SQL Injection Attempt",
-		"ET MALWARE Ransomware CnC Beacon",
-		"ET SCAN Masscan Detected",
-	}
-	sig := signatures[rand.Intn(len(signatures))]
+	sig := IDSSignatures[rand.Intn(len(IDSSignatures))]
 
 	sid := rand.Intn(9000000) + 1000000
 	rev := rand.Intn(10) + 1
 
-	return fmt.Sprintf("<%d>%s %s snort[%d]: [1:%d:%d] %s {TCP} %s:%d -> %s:%d",
-		priority,
-		now.Format("Jan  2 15:04:05"),
-		hostname,
-		rand.Intn(10000)+1000,
-		sid,
-		rev,
-		sig,
-		srcIP,
-		srcPort,
-		dstIP,
-		dstPort,
-	)
+	return &SyslogMessage{
+		Time:     time.Now(),
+		Priority: priority,
+		Hostname: hostname,
+		AppName:  "snort",
+		ProcID:   strconv.Itoa(rand.Intn(10000) + 1000),
+		MsgID:    "ALERT",
+		StructuredData: []SDElement{
+			{ID: sdID("ids"), Params: []SDParam{
+				{"gid", "1"},
+				{"sid", strconv.Itoa(sid)},
+				{"rev", strconv.Itoa(rev)},
+				{"msg", sig},
+				{"proto", "TCP"},
+				{"src", srcIP},
+				{"spt", strconv.Itoa(srcPort)},
+				{"dst", dstIP},
+				{"dpt", strconv.Itoa(dstPort)},
+			}},
+			originSD(fmt.Sprintf("10.253.0.%d", n), "snort"),
+			metaSD(),
+		},
+		Msg: fmt.Sprintf("[1:%d:%d] %s {TCP} %s:%d -> %s:%d",
+			sid,
+			rev,
+			sig,
+			srcIP,
+			srcPort,
+			dstIP,
+			dstPort,
+		),
+	}
+}
+
+// GenerateIDSSyslog generates IDS/IPS style alerts
+func GenerateIDSSyslog(opts Options) string {
+	return NewIDSEvent().Apply(opts).Render(opts)
 }
@@ -0,0 +1,219 @@
+// Licensed under Elastic License 2.0
+// See LICENSE.txt for details
+
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ExamplePEN is the private enterprise number reserved for documentation
+// (RFC 5612), used in the SD-IDs of type-specific structured data
+const ExamplePEN = 32473
+
+// SDParam is one PARAM-NAME="PARAM-VALUE" pair of an SD-ELEMENT
+type SDParam struct {
+	Name  string
+	Value string
+}
+
+// SDElement is one [SD-ID PARAM...] block of RFC 5424 structured data
+type SDElement struct {
+	ID     string
+	Params []SDParam
+}
+
+// SyslogMessage is a generated syslog event before rendering. The same event
+// renders as RFC 3164 or RFC 5424; structured data and MSGID only appear in
+// RFC 5424.
+type SyslogMessage struct {
+	Time           time.Time
+	Priority       int
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string
+	StructuredData []SDElement
+	Msg            string
+	// BSDMsg, when set, replaces Msg in RFC 3164 output, for devices whose
+	// BSD-style messages carry what RFC 5424 puts in MSGID or SD
+	BSDMsg string
+	// Bare drops the RFC 3164 timestamp and hostname, leaving <PRI>MSG as
+	// FortiGate's default log format does
+	Bare bool
+	// Header is the header variant to render (HeaderStandard, ...)
+	Header int
+}
+
+// syslogSequence numbers messages for the meta sequenceId parameter
+var syslogSequence atomic.Int64
+
+// param returns a parameter of the first structured data element, which
+// holds the type-specific fields of generated events
+func (m *SyslogMessage) param(name string) string {
+	if len(m.StructuredData) == 0 {
+		return ""
+	}
+	for _, p := range m.StructuredData[0].Params {
+		if p.Name == name {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// metaSD returns a meta element with the next sequenceId, which RFC 5424
+// wraps from 2147483647 back to 1
+func metaSD() SDElement {
+	seq := (syslogSequence.Add(1)-1)%2147483647 + 1
+	return SDElement{ID: "meta", Params: []SDParam{{"sequenceId", strconv.FormatInt(seq, 10)}}}
+}
+
+// originSD returns an origin element for the sending host and software
+func originSD(ip, software string) SDElement {
+	return SDElement{ID: "origin", Params: []SDParam{
+		{"ip", ip},
+		{"enterpriseId", strconv.Itoa(ExamplePEN)},
+		{"software", software},
+	}}
+}
+
+// sdID returns a private SD-ID in the documentation enterprise number space
+func sdID(name string) string {
+	return fmt.Sprintf("%s@%d", name, ExamplePEN)
+}
+
+// escapeSDValue escapes '"', '\' and ']' in a PARAM-VALUE (RFC 5424 6.3.3)
+func escapeSDValue(v string) string {
+	if !strings.ContainsAny(v, `"\]`) {
+		return v
+	}
+	var b strings.Builder
+	for _, r := range v {
+		if r == '"' || r == '\\' || r == ']' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// nilValue returns the RFC 5424 NILVALUE for empty header fields
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// appendStructuredData renders elements, or the NILVALUE when there are none
+func appendStructuredData(b []byte, elements []SDElement) []byte {
+	if len(elements) == 0 {
+		return append(b, '-')
+	}
+	for _, e := range elements {
+		b = append(b, '[')
+		b = append(b, e.ID...)
+		for _, p := range e.Params {
+			b = append(b, ' ')
+			b = append(b, p.Name...)
+			b = append(b, '=', '"')
+			b = append(b, escapeSDValue(p.Value)...)
+			b = append(b, '"')
+		}
+		b = append(b, ']')
+	}
+	return b
+}
+
+// Apply overrides the facility and severity from the weighted mixes in opts
+// and picks a header variant. It returns m for chaining.
+func (m *SyslogMessage) Apply(opts Options) *SyslogMessage {
+	if len(opts.Facilities) > 0 {
+		m.Priority = pickWeighted(opts.Facilities)*8 + m.Priority%8
+	}
+	if len(opts.Severities) > 0 {
+		m.Priority = m.Priority/8*8 + pickWeighted(opts.Severities)
+	}
+	if len(opts.Headers) > 0 {
+		m.Header = pickWeighted(opts.Headers)
+		if m.Header == HeaderTZ {
+			m.Time = m.Time.In(headerZones[rand.Intn(len(headerZones))])
+		}
+	}
+	return m
+}
+
+// Render formats the message in the syslog format selected by opts.RFC
+func (m *SyslogMessage) Render(opts Options) string {
+	if opts.RFC == "5424" {
+		return m.render5424(opts)
+	}
+	return m.render3164()
+}
+
+// render3164 formats <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
+func (m *SyslogMessage) render3164() string {
+	layout := "Jan _2 15:04:05"
+	switch m.Header {
+	case HeaderISO:
+		layout = "2006-01-02T15:04:05.000000Z07:00"
+	case HeaderYear:
+		layout = "Jan _2 2006 15:04:05"
+	case HeaderTZ:
+		layout = "Jan _2 15:04:05 MST"
+	}
+	msg := m.Msg
+	if m.BSDMsg != "" {
+		msg = m.BSDMsg
+	}
+	if m.Bare {
+		return fmt.Sprintf("<%d>%s", m.Priority, msg)
+	}
+	b := make([]byte, 0, 64+len(msg))
+	b = fmt.Appendf(b, "<%d>%s ", m.Priority, m.Time.Format(layout))
+	if m.Header != HeaderNoHostname {
+		b = append(b, m.Hostname...)
+		b = append(b, ' ')
+	}
+	// Without an app name the message follows the hostname directly, as
+	// CEF and LEEF senders do
+	if m.AppName != "" {
+		b = append(b, m.AppName...)
+		if m.ProcID != "" {
+			b = append(b, '[')
+			b = append(b, m.ProcID...)
+			b = append(b, ']')
+		}
+		b = append(b, ':', ' ')
+	}
+	b = append(b, msg...)
+	return string(b)
+}
+
+// render5424 formats <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD [MSG],
+// prefixing MSG with a UTF-8 BOM when opts.BOM is set
+func (m *SyslogMessage) render5424(opts Options) string {
+	hostname := m.Hostname
+	if m.Header == HeaderNoHostname {
+		hostname = ""
+	}
+	b := make([]byte, 0, 256+len(m.Msg))
+	b = fmt.Appendf(b, "<%d>1 %s %s %s %s %s ", m.Priority,
+		m.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		nilValue(hostname), nilValue(m.AppName), nilValue(m.ProcID), nilValue(m.MsgID))
+	b = appendStructuredData(b, m.StructuredData)
+	if m.Msg != "" {
+		b = append(b, ' ')
+		if opts.BOM {
+			b = append(b, 0xEF, 0xBB, 0xBF)
+		}
+		b = append(b, m.Msg...)
+	}
+	return string(b)
+}